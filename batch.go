@@ -0,0 +1,103 @@
+package ringslice
+
+// AppendMany adds values oldest first with at most two copies across the wrap point and
+// returns how many were added. Under FullReject it adds what fits and returns ErrFull if
+// that wasn't all of them. FullOverwrite evicts the oldest as needed, keeping only the
+// last Cap() if there are more, FullGrow resizes once and FullBlock waits for space
+func (s *Slice[T]) AppendMany(values []T) (int, error) {
+	s.lock()
+	defer s.unlock()
+	switch s.full {
+	case FullOverwrite:
+		s.overwriteMany(values)
+		return len(values), nil
+	case FullGrow:
+		s.growFor(len(values))
+		s.copyIn(values)
+		return len(values), nil
+	case FullBlock:
+		n := 0
+		for n < len(values) {
+			if err := s.waitForRoom(1); err != nil {
+				return n, err
+			}
+			n += s.copyIn(values[n:])
+		}
+		return n, nil
+	}
+	n := s.copyIn(values)
+	if n < len(values) {
+		return n, ErrFull
+	}
+	return n, nil
+}
+
+// AppendAll adds every value or none. Under FullReject and FullBlock it returns ErrFull
+// without adding anything if they can't all fit, FullBlock first waiting for room if
+// they could ever fit
+func (s *Slice[T]) AppendAll(values []T) error {
+	s.lock()
+	defer s.unlock()
+	return s.appendAll(values)
+}
+
+func (s *Slice[T]) appendAll(values []T) error {
+	switch s.full {
+	case FullOverwrite:
+		s.overwriteMany(values)
+		return nil
+	case FullGrow:
+		s.growFor(len(values))
+	case FullBlock:
+		if err := s.waitForRoom(len(values)); err != nil {
+			return err
+		}
+	}
+	if s.cap-s.used < len(values) {
+		return ErrFull
+	}
+	s.copyIn(values)
+	return nil
+}
+
+// copyIn copies as many values as fit after the newest entry and returns how many
+func (s *Slice[T]) copyIn(values []T) int {
+	free := s.cap - s.used
+	if free < len(values) {
+		values = values[:free]
+	}
+	if len(values) == 0 {
+		return 0
+	}
+	end := s.trueIndex(s.start, s.used)
+	n := copy(s.values[end:], values)
+	copy(s.values, values[n:]) // wrapped part, if any
+	s.used += len(values)
+	s.mods++
+	return len(values)
+}
+
+// overwriteMany evicts the oldest to fit values, only the last cap survive
+func (s *Slice[T]) overwriteMany(values []T) {
+	if len(values) > s.cap {
+		values = values[len(values)-s.cap:]
+	}
+	for s.used+len(values) > s.cap {
+		s.popFront()
+	}
+	s.copyIn(values)
+}
+
+// growFor doubles until n more fit, resizing at most once
+func (s *Slice[T]) growFor(n int) {
+	newCap := s.cap
+	for newCap-s.used < n {
+		if newCap == 0 {
+			newCap = 1
+		}
+		newCap *= 2
+	}
+	if newCap != s.cap {
+		s.resize(newCap)
+	}
+}
@@ -0,0 +1,138 @@
+package ringslice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendMany(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		append     []int
+		wantN      int
+		wantErr    error
+		wantValues []int
+		want       []int
+	}{
+		{
+			name:       "fits without wrap",
+			append:     []int{5, 6},
+			wantN:      2,
+			wantValues: []int{0, 0, 3, 4, 5, 6},
+			want:       []int{3, 4, 5, 6},
+		},
+		{
+			name:       "fits across wrap",
+			append:     []int{5, 6, 7, 8},
+			wantN:      4,
+			wantValues: []int{7, 8, 3, 4, 5, 6},
+			want:       []int{3, 4, 5, 6, 7, 8},
+		},
+		{
+			name:       "partial",
+			append:     []int{5, 6, 7, 8, 9},
+			wantN:      4,
+			wantErr:    ErrFull,
+			wantValues: []int{7, 8, 3, 4, 5, 6},
+			want:       []int{3, 4, 5, 6, 7, 8},
+		},
+		{
+			name:       "overwrite",
+			opts:       []Option{WithFullPolicy(FullOverwrite)},
+			append:     []int{5, 6, 7, 8, 9},
+			wantN:      5,
+			wantValues: []int{7, 8, 9, 4, 5, 6},
+			want:       []int{4, 5, 6, 7, 8, 9},
+		},
+		{
+			name:       "overwrite more than cap",
+			opts:       []Option{WithFullPolicy(FullOverwrite)},
+			append:     []int{10, 11, 12, 13, 14, 15, 16},
+			wantN:      7,
+			wantValues: []int{13, 14, 15, 16, 11, 12},
+			want:       []int{11, 12, 13, 14, 15, 16},
+		},
+		{
+			name:       "grow",
+			opts:       []Option{WithFullPolicy(FullGrow)},
+			append:     []int{5, 6, 7, 8, 9},
+			wantN:      5,
+			wantValues: []int{3, 4, 5, 6, 7, 8, 9, 0, 0, 0, 0, 0},
+			want:       []int{3, 4, 5, 6, 7, 8, 9},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 3, 4 held at indices 2 and 3 of 6
+			s := NewSlice[int](6, false, nil, tt.opts...)
+			s.AppendMany([]int{1, 2, 3, 4})
+			s.DeleteCount(2)
+
+			n, err := s.AppendMany(tt.append)
+			require.Equal(t, tt.wantN, n)
+			require.Equal(t, tt.wantErr, err)
+			require.Equal(t, tt.wantValues, s.values)
+			a, b := s.Segments()
+			require.Equal(t, tt.want, append(a, b...))
+		})
+	}
+}
+
+func TestAppendAll(t *testing.T) {
+	s := NewSlice[int](3, false, nil)
+	require.NoError(t, s.AppendAll([]int{1, 2}))
+	require.Equal(t, ErrFull, s.AppendAll([]int{3, 4}))
+	require.Equal(t, 2, s.Len())
+	require.NoError(t, s.AppendAll([]int{3}))
+	require.NoError(t, s.AppendAll(nil))
+
+	s = NewSlice[int](3, false, nil, WithFullPolicy(FullOverwrite))
+	require.NoError(t, s.AppendAll([]int{1, 2, 3, 4}))
+	require.Equal(t, []int{2, 3, 4}, s.DeleteCount(3))
+}
+
+func TestAppendManyBlock(t *testing.T) {
+	s := NewSlice[int](2, false, nil, WithFullPolicy(FullBlock))
+	require.Equal(t, ErrFull, s.AppendAll([]int{1, 2, 3}))
+
+	done := make(chan int)
+	go func() {
+		n, _ := s.AppendMany([]int{1, 2, 3, 4})
+		done <- n
+	}()
+	var got []int
+	for len(got) < 4 {
+		v, err := s.PopFront()
+		if err != nil {
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		got = append(got, v)
+	}
+	require.Equal(t, 4, <-done)
+	require.Equal(t, []int{1, 2, 3, 4}, got)
+}
+
+func BenchmarkAppend(b *testing.B) {
+	s := NewSlice[int](1024, false, nil)
+	buf := make([]int, 0, 1000)
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 1000; j++ {
+			s.Append(j)
+		}
+		buf = s.DeleteCountInto(buf[:0], 1000)
+	}
+}
+
+func BenchmarkAppendMany(b *testing.B) {
+	s := NewSlice[int](1024, false, nil)
+	values := make([]int, 1000)
+	buf := make([]int, 0, 1000)
+	for i := 0; i < b.N; i++ {
+		s.AppendMany(values)
+		buf = s.DeleteCountInto(buf[:0], 1000)
+	}
+}
@@ -0,0 +1,111 @@
+package ringslice
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BucketRing is a circular histogram over time. Each slot totals the deltas added in one
+// fixed width interval, and as time moves forward the slots that fall off the back are
+// zeroed and reused for the new intervals, so memory stays at one int64 per bucket
+type BucketRing struct {
+	mu     sync.Mutex
+	counts []int64
+	width  time.Duration
+	newest int64 // interval number of the newest bucket, time / width
+	clock  Clock
+}
+
+// NewBucketRing creates a ring of buckets each width long, covering buckets*width of
+// history. WithClock replaces RealClock
+func NewBucketRing(buckets int, width time.Duration, opts ...Option) (*BucketRing, error) {
+	if buckets <= 0 || width <= 0 {
+		return nil, fmt.Errorf("ringslice: buckets and width must be positive")
+	}
+	c := newConfig(opts)
+	clock := c.clock
+	if clock == nil {
+		clock = RealClock
+	}
+	b := &BucketRing{counts: make([]int64, buckets), width: width, clock: clock}
+	b.newest = b.interval(clock.Now())
+	return b, nil
+}
+
+// Add adds delta to the bucket holding t, rotating forward if t is newer than the newest
+// bucket. Returns ErrExpired if t is older than the oldest bucket
+func (b *BucketRing) Add(t time.Time, delta int64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := b.interval(t)
+	b.rotate(n)
+	if n <= b.newest-int64(len(b.counts)) {
+		return ErrExpired
+	}
+	b.counts[b.trueIndex(n)] += delta
+	return nil
+}
+
+// Total sums the buckets overlapping the trailing last duration, the current bucket included
+func (b *BucketRing) Total(last time.Duration) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rotate(b.interval(b.clock.Now()))
+	k := int64((last + b.width - 1) / b.width)
+	if k > int64(len(b.counts)) {
+		k = int64(len(b.counts))
+	}
+	var total int64
+	for i := int64(0); i < k; i++ {
+		total += b.counts[b.trueIndex(b.newest-i)]
+	}
+	return total
+}
+
+// Snapshot copies the buckets oldest to newest as of now
+func (b *BucketRing) Snapshot() []int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rotate(b.interval(b.clock.Now()))
+	l := make([]int64, len(b.counts))
+	oldest := b.newest - int64(len(b.counts)) + 1
+	for i := range l {
+		l[i] = b.counts[b.trueIndex(oldest+int64(i))]
+	}
+	return l
+}
+
+// rotate zeroes every bucket between the newest and interval n, must hold mu
+func (b *BucketRing) rotate(n int64) {
+	if n <= b.newest {
+		return
+	}
+	stale := n - b.newest
+	if stale > int64(len(b.counts)) {
+		stale = int64(len(b.counts))
+	}
+	for i := int64(0); i < stale; i++ {
+		b.counts[b.trueIndex(n-i)] = 0
+	}
+	b.newest = n
+}
+
+// interval numbers time in widths, rounding down before the epoch too
+func (b *BucketRing) interval(t time.Time) int64 {
+	ns := t.UnixNano()
+	n := ns / int64(b.width)
+	if ns < 0 && ns%int64(b.width) != 0 {
+		n--
+	}
+	return n
+}
+
+// trueIndex maps an interval number to its slot
+func (b *BucketRing) trueIndex(n int64) int {
+	i := n % int64(len(b.counts))
+	if i < 0 {
+		i += int64(len(b.counts))
+	}
+	return int(i)
+}
@@ -0,0 +1,120 @@
+package ringslice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketRing(t *testing.T) {
+	type add struct {
+		at      time.Duration // after epoch
+		delta   int64
+		wantErr error
+	}
+	tests := []struct {
+		name         string
+		adds         []add
+		now          time.Duration
+		wantSnapshot []int64
+		wantLast2s   int64
+	}{
+		{
+			name:         "same bucket",
+			adds:         []add{{at: 0, delta: 1}, {at: 999 * time.Millisecond, delta: 2}},
+			wantSnapshot: []int64{0, 0, 0, 3},
+			wantLast2s:   3,
+		},
+		{
+			name:         "spread",
+			adds:         []add{{at: 0, delta: 1}, {at: time.Second, delta: 2}, {at: 3 * time.Second, delta: 4}},
+			now:          3 * time.Second,
+			wantSnapshot: []int64{1, 2, 0, 4},
+			wantLast2s:   4,
+		},
+		{
+			name: "rotation zeroes stale",
+			adds: []add{
+				{at: 0, delta: 1},
+				{at: time.Second, delta: 2},
+				{at: 5 * time.Second, delta: 4}, // drops 0 and 1
+			},
+			now:          5 * time.Second,
+			wantSnapshot: []int64{0, 0, 0, 4},
+			wantLast2s:   4,
+		},
+		{
+			name: "late but still held",
+			adds: []add{
+				{at: 3 * time.Second, delta: 1},
+				{at: time.Second, delta: 2},
+			},
+			now:          3 * time.Second,
+			wantSnapshot: []int64{0, 2, 0, 1},
+			wantLast2s:   1,
+		},
+		{
+			name: "too late",
+			adds: []add{
+				{at: 4 * time.Second, delta: 1},
+				{at: 0, delta: 2, wantErr: ErrExpired},
+			},
+			now:          4 * time.Second,
+			wantSnapshot: []int64{0, 0, 0, 1},
+			wantLast2s:   1,
+		},
+		{
+			name:         "clock moves past everything",
+			adds:         []add{{at: 0, delta: 1}},
+			now:          time.Hour,
+			wantSnapshot: []int64{0, 0, 0, 0},
+			wantLast2s:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewManualClock(epoch)
+			b, err := NewBucketRing(4, time.Second, WithClock(clock))
+			require.NoError(t, err)
+			for _, a := range tt.adds {
+				require.Equal(t, a.wantErr, b.Add(epoch.Add(a.at), a.delta))
+			}
+			clock.Set(epoch.Add(tt.now))
+			require.Equal(t, tt.wantSnapshot, b.Snapshot())
+			require.Equal(t, tt.wantLast2s, b.Total(1500*time.Millisecond))
+		})
+	}
+}
+
+func TestBucketRingTotal(t *testing.T) {
+	clock := NewManualClock(epoch)
+	b, err := NewBucketRing(4, time.Second, WithClock(clock))
+	require.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		b.Add(epoch.Add(time.Duration(i)*time.Second), int64(i+1))
+	}
+	clock.Advance(3 * time.Second)
+	require.Equal(t, int64(0), b.Total(0))
+	require.Equal(t, int64(4), b.Total(time.Second))
+	require.Equal(t, int64(7), b.Total(2*time.Second))
+	require.Equal(t, int64(10), b.Total(time.Hour))
+}
+
+func TestBucketRingBeforeEpoch(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	b, err := NewBucketRing(2, time.Second, WithClock(clock))
+	require.NoError(t, err)
+	require.NoError(t, b.Add(time.Unix(0, -1), 1))
+	require.Equal(t, []int64{1, 0}, b.Snapshot())
+}
+
+func TestBucketRingArgs(t *testing.T) {
+	for _, args := range []struct {
+		buckets int
+		width   time.Duration
+	}{{0, time.Second}, {-1, time.Second}, {4, 0}, {4, -time.Second}} {
+		_, err := NewBucketRing(args.buckets, args.width)
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,230 @@
+package ringslice
+
+import "io"
+
+// ByteRing is a fixed capacity byte buffer with the same start, used, cap layout as
+// Slice, moving data with at most two copies across the wrap point. Writes follow
+// the FullPolicy: FullReject makes a short write with ErrFull, FullOverwrite drops
+// the oldest bytes and FullGrow doubles. FullBlock is left to Pipe and acts as FullReject
+type ByteRing struct {
+	buf   []byte
+	start int
+	used  int
+	cap   int
+	full  FullPolicy
+}
+
+// NewByteRing creates a byte ring of capacity
+func NewByteRing(capacity int, opts ...Option) *ByteRing {
+	c := newConfig(opts)
+	return &ByteRing{buf: make([]byte, capacity), cap: capacity, full: c.full}
+}
+
+// Len is the number of unread bytes
+func (b *ByteRing) Len() int {
+	return b.used
+}
+
+// Cap is the number of bytes that fit
+func (b *ByteRing) Cap() int {
+	return b.cap
+}
+
+// Free is the number of bytes that can be written without hitting the FullPolicy
+func (b *ByteRing) Free() int {
+	return b.cap - b.used
+}
+
+// Reset drops everything unread
+func (b *ByteRing) Reset() {
+	b.start = 0
+	b.used = 0
+}
+
+// Read implements io.Reader, returning io.EOF when empty
+func (b *ByteRing) Read(p []byte) (int, error) {
+	if b.used == 0 {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+	n := b.peek(p)
+	b.discard(n)
+	return n, nil
+}
+
+// ReadByte implements io.ByteReader
+func (b *ByteRing) ReadByte() (byte, error) {
+	if b.used == 0 {
+		return 0, io.EOF
+	}
+	c := b.buf[b.start]
+	b.discard(1)
+	return c, nil
+}
+
+// Write implements io.Writer
+func (b *ByteRing) Write(p []byte) (int, error) {
+	switch b.full {
+	case FullOverwrite:
+		b.overwrite(p)
+		return len(p), nil
+	case FullGrow:
+		b.growFor(len(p))
+	}
+	n := b.copyIn(p)
+	if n < len(p) {
+		return n, ErrFull
+	}
+	return n, nil
+}
+
+// WriteByte implements io.ByteWriter
+func (b *ByteRing) WriteByte(c byte) error {
+	_, err := b.Write([]byte{c})
+	return err
+}
+
+// WriteTo implements io.WriterTo, draining the ring into w
+func (b *ByteRing) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for b.used > 0 {
+		end := b.start + b.used
+		if end > b.cap {
+			end = b.cap
+		}
+		n, err := w.Write(b.buf[b.start:end])
+		if n > end-b.start {
+			n = end - b.start // misbehaving writer
+		}
+		b.discard(n)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+		if n == 0 {
+			return total, io.ErrShortWrite
+		}
+	}
+	return total, nil
+}
+
+// ReadFrom implements io.ReaderFrom, reading from r until io.EOF. Under FullReject it
+// stops with ErrFull once there is no room left, even if r had nothing more to give
+func (b *ByteRing) ReadFrom(r io.Reader) (int64, error) {
+	var total int64
+	var scratch []byte // only for FullOverwrite, where reads can land on unread bytes
+	for {
+		p := b.tail()
+		if b.used == b.cap {
+			switch b.full {
+			case FullOverwrite:
+				if scratch == nil {
+					scratch = make([]byte, b.cap)
+				}
+				p = scratch
+			case FullGrow:
+				b.growFor(1)
+				p = b.tail()
+			default:
+				return total, ErrFull
+			}
+		}
+		if len(p) == 0 {
+			return total, ErrFull // zero capacity
+		}
+		n, err := r.Read(p)
+		if n > 0 {
+			if b.used == b.cap && b.full == FullOverwrite {
+				b.overwrite(p[:n])
+			} else {
+				b.used += n
+			}
+			total += int64(n)
+		}
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// peek copies the oldest bytes into p without consuming them
+func (b *ByteRing) peek(p []byte) int {
+	end := b.start + b.used
+	if end <= b.cap {
+		return copy(p, b.buf[b.start:end])
+	}
+	n := copy(p, b.buf[b.start:])
+	return n + copy(p[n:], b.buf[:end-b.cap])
+}
+
+// discard consumes n of the oldest bytes
+func (b *ByteRing) discard(n int) {
+	b.used -= n
+	if b.used == 0 {
+		b.start = 0 // keep the next write contiguous
+		return
+	}
+	b.start = (b.start + n) % b.cap
+}
+
+// tail is the contiguous free region after the newest byte
+func (b *ByteRing) tail() []byte {
+	if b.used == b.cap {
+		return nil
+	}
+	end := (b.start + b.used) % b.cap
+	if end < b.start {
+		return b.buf[end:b.start]
+	}
+	return b.buf[end:]
+}
+
+// copyIn copies as much of p as fits after the newest byte and returns how much
+func (b *ByteRing) copyIn(p []byte) int {
+	if free := b.cap - b.used; free < len(p) {
+		p = p[:free]
+	}
+	if len(p) == 0 {
+		return 0
+	}
+	end := (b.start + b.used) % b.cap
+	n := copy(b.buf[end:], p)
+	copy(b.buf, p[n:])
+	b.used += len(p)
+	return len(p)
+}
+
+// overwrite drops the oldest bytes to fit p, only the last cap of p survive
+func (b *ByteRing) overwrite(p []byte) {
+	if len(p) > b.cap {
+		p = p[len(p)-b.cap:]
+	}
+	if over := b.used + len(p) - b.cap; over > 0 {
+		b.discard(over)
+	}
+	b.copyIn(p)
+}
+
+// growFor doubles until n more bytes fit, relinearizing once
+func (b *ByteRing) growFor(n int) {
+	newCap := b.cap
+	for newCap-b.used < n {
+		if newCap == 0 {
+			newCap = 1
+		}
+		newCap *= 2
+	}
+	if newCap == b.cap {
+		return
+	}
+	buf := make([]byte, newCap)
+	b.peek(buf)
+	b.buf = buf
+	b.cap = newCap
+	b.start = 0
+}
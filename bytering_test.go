@@ -0,0 +1,177 @@
+package ringslice
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+// wrappedByteRing holds "cdef" across the end of a 6 byte buffer
+func wrappedByteRing(opts ...Option) *ByteRing {
+	b := NewByteRing(6, opts...)
+	b.Write([]byte("xxxxcd"))
+	b.Read(make([]byte, 4))
+	b.Write([]byte("ef"))
+	return b
+}
+
+func TestByteRingIOTest(t *testing.T) {
+	content := []byte("hello, ring")
+	b := NewByteRing(16)
+	_, err := b.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, iotest.TestReader(b, content))
+
+	b = wrappedByteRing()
+	require.NoError(t, iotest.TestReader(b, []byte("cdef")))
+}
+
+func TestByteRingWrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		write   string
+		wantN   int
+		wantErr error
+		want    string
+		wantCap int
+	}{
+		{name: "fits", write: "gh", wantN: 2, want: "cdefgh", wantCap: 6},
+		{name: "short", write: "ghi", wantN: 2, wantErr: ErrFull, want: "cdefgh", wantCap: 6},
+		{
+			name:    "overwrite",
+			opts:    []Option{WithFullPolicy(FullOverwrite)},
+			write:   "ghi",
+			wantN:   3,
+			want:    "defghi",
+			wantCap: 6,
+		},
+		{
+			name:    "overwrite all",
+			opts:    []Option{WithFullPolicy(FullOverwrite)},
+			write:   "0123456789",
+			wantN:   10,
+			want:    "456789",
+			wantCap: 6,
+		},
+		{
+			name:    "grow",
+			opts:    []Option{WithFullPolicy(FullGrow)},
+			write:   "ghi",
+			wantN:   3,
+			want:    "cdefghi",
+			wantCap: 12,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := wrappedByteRing(tt.opts...)
+			n, err := b.Write([]byte(tt.write))
+			require.Equal(t, tt.wantN, n)
+			require.Equal(t, tt.wantErr, err)
+			require.Equal(t, tt.wantCap, b.Cap())
+			got, err := io.ReadAll(b)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestByteRingBytes(t *testing.T) {
+	b := NewByteRing(2)
+	require.NoError(t, b.WriteByte('a'))
+	require.NoError(t, b.WriteByte('b'))
+	require.Equal(t, ErrFull, b.WriteByte('c'))
+	c, err := b.ReadByte()
+	require.NoError(t, err)
+	require.Equal(t, byte('a'), c)
+	require.NoError(t, b.WriteByte('c'))
+	var got []byte
+	for {
+		c, err := b.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		got = append(got, c)
+	}
+	require.Equal(t, "bc", string(got))
+}
+
+func TestByteRingWriteTo(t *testing.T) {
+	b := wrappedByteRing()
+	var out bytes.Buffer
+	n, err := b.WriteTo(&out)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), n)
+	require.Equal(t, "cdef", out.String())
+	require.Equal(t, 0, b.Len())
+
+	b = wrappedByteRing()
+	boom := errors.New("boom")
+	_, err = b.WriteTo(errWriter{boom})
+	require.Equal(t, boom, err)
+	require.Equal(t, 4, b.Len())
+}
+
+type errWriter struct{ err error }
+
+func (w errWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func TestByteRingReadFrom(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		src     io.Reader
+		wantN   int64
+		wantErr error
+		want    string
+	}{
+		{name: "fits", src: strings.NewReader("g"), wantN: 1, want: "cdefg"},
+		{name: "one byte reader", src: iotest.OneByteReader(strings.NewReader("g")), wantN: 1, want: "cdefg"},
+		{name: "exactly full", src: strings.NewReader("gh"), wantN: 2, wantErr: ErrFull, want: "cdefgh"},
+		{name: "data with eof", src: iotest.DataErrReader(strings.NewReader("g")), wantN: 1, want: "cdefg"},
+		{name: "full", src: strings.NewReader("ghi"), wantN: 2, wantErr: ErrFull, want: "cdefgh"},
+		{name: "error", src: iotest.ErrReader(io.ErrUnexpectedEOF), wantErr: io.ErrUnexpectedEOF, want: "cdef"},
+		{
+			name:  "overwrite",
+			opts:  []Option{WithFullPolicy(FullOverwrite)},
+			src:   iotest.HalfReader(strings.NewReader("0123456789")),
+			wantN: 10,
+			want:  "456789",
+		},
+		{
+			name:  "grow",
+			opts:  []Option{WithFullPolicy(FullGrow)},
+			src:   strings.NewReader("0123456789"),
+			wantN: 10,
+			want:  "cdef0123456789",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := wrappedByteRing(tt.opts...)
+			n, err := b.ReadFrom(tt.src)
+			require.Equal(t, tt.wantN, n)
+			require.Equal(t, tt.wantErr, err)
+			got, _ := io.ReadAll(b)
+			require.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestByteRingCopy(t *testing.T) {
+	// io.Copy uses WriterTo and ReaderFrom when they're there
+	src := NewByteRing(64)
+	src.Write(bytes.Repeat([]byte("ab"), 32))
+	dst := NewByteRing(64)
+	n, err := io.Copy(dst, src)
+	require.NoError(t, err)
+	require.Equal(t, int64(64), n)
+	require.NoError(t, iotest.TestReader(dst, bytes.Repeat([]byte("ab"), 32)))
+}
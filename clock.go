@@ -0,0 +1,173 @@
+package ringslice
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the time source for the time based rings so tests can drive them without sleeping
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Ticker is the part of time.Ticker the rings use
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Timer is the part of time.Timer the rings use
+type Timer interface {
+	Stop() bool
+}
+
+// RealClock is backed by the time package
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// ManualClock only moves when told to. Tickers and timers that come due during
+// Advance or Set fire in time order, AfterFunc callbacks run on the caller's goroutine
+type ManualClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*manualWaiter
+}
+
+// NewManualClock creates a clock stopped at now
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+type manualWaiter struct {
+	clock  *ManualClock
+	when   time.Time
+	period time.Duration // 0 for a one shot AfterFunc
+	f      func()
+	c      chan time.Time
+}
+
+// Now returns the manual time
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTicker ticks every d of manual time. Like time.Ticker it drops ticks for slow receivers
+func (c *ManualClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("ringslice: non-positive interval for NewTicker")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &manualWaiter{clock: c, when: c.now.Add(d), period: d, c: make(chan time.Time, 1)}
+	c.waiters = append(c.waiters, w)
+	return manualTicker{w}
+}
+
+// AfterFunc calls f once d of manual time has passed
+func (c *ManualClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &manualWaiter{clock: c, when: c.now.Add(d), f: f}
+	c.waiters = append(c.waiters, w)
+	return manualTimer{w}
+}
+
+// Advance moves the clock forward by d
+func (c *ManualClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the clock to t, firing everything due on the way
+func (c *ManualClock) Set(t time.Time) {
+	for {
+		c.mu.Lock()
+		sort.SliceStable(c.waiters, func(i, j int) bool {
+			return c.waiters[i].when.Before(c.waiters[j].when)
+		})
+		if len(c.waiters) == 0 || c.waiters[0].when.After(t) {
+			c.now = t
+			c.mu.Unlock()
+			return
+		}
+		w := c.waiters[0]
+		if w.when.After(c.now) {
+			c.now = w.when
+		}
+		if w.period > 0 {
+			w.when = w.when.Add(w.period)
+		} else {
+			c.waiters = c.waiters[1:]
+		}
+		now := c.now
+		c.mu.Unlock()
+
+		if w.f != nil {
+			w.f()
+			continue
+		}
+		select {
+		case w.c <- now:
+		default:
+		}
+	}
+}
+
+type manualTicker struct {
+	*manualWaiter
+}
+
+func (t manualTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t manualTicker) Stop() {
+	t.remove()
+}
+
+type manualTimer struct {
+	*manualWaiter
+}
+
+func (t manualTimer) Stop() bool {
+	return t.remove()
+}
+
+// remove reports whether the waiter was still pending
+func (w *manualWaiter) remove() bool {
+	c := w.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, o := range c.waiters {
+		if o == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
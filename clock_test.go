@@ -0,0 +1,73 @@
+package ringslice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManualClockAfterFunc(t *testing.T) {
+	c := NewManualClock(epoch)
+	var fired []string
+	c.AfterFunc(2*time.Second, func() { fired = append(fired, "two") })
+	c.AfterFunc(time.Second, func() { fired = append(fired, "one") })
+	stopped := c.AfterFunc(time.Second, func() { fired = append(fired, "stopped") })
+	require.True(t, stopped.Stop())
+	require.False(t, stopped.Stop())
+
+	c.Advance(500 * time.Millisecond)
+	require.Empty(t, fired)
+	c.Advance(2 * time.Second)
+	require.Equal(t, []string{"one", "two"}, fired)
+	require.Equal(t, epoch.Add(2500*time.Millisecond), c.Now())
+}
+
+func TestManualClockAfterFuncSeesDueTime(t *testing.T) {
+	c := NewManualClock(epoch)
+	var at time.Time
+	c.AfterFunc(time.Second, func() { at = c.Now() })
+	c.Advance(time.Hour)
+	require.Equal(t, epoch.Add(time.Second), at)
+}
+
+func TestManualClockTicker(t *testing.T) {
+	c := NewManualClock(epoch)
+	tk := c.NewTicker(time.Second)
+
+	c.Advance(999 * time.Millisecond)
+	select {
+	case <-tk.C():
+		t.Fatal("ticked early")
+	default:
+	}
+
+	c.Advance(time.Millisecond)
+	require.Equal(t, epoch.Add(time.Second), <-tk.C())
+
+	c.Advance(3 * time.Second) // slow receiver keeps only the first
+	require.Equal(t, epoch.Add(2*time.Second), <-tk.C())
+	select {
+	case <-tk.C():
+		t.Fatal("ticks were not dropped")
+	default:
+	}
+
+	tk.Stop()
+	c.Advance(time.Minute)
+	select {
+	case <-tk.C():
+		t.Fatal("ticked after stop")
+	default:
+	}
+}
+
+func TestRealClock(t *testing.T) {
+	done := make(chan struct{})
+	RealClock.AfterFunc(time.Millisecond, func() { close(done) })
+	<-done
+	tk := RealClock.NewTicker(time.Millisecond)
+	<-tk.C()
+	tk.Stop()
+	require.False(t, RealClock.Now().IsZero())
+}
@@ -0,0 +1,109 @@
+package ringslice
+
+import (
+	"context"
+	"sync"
+)
+
+// ConcurrentSlice guards a Slice with a mutex so producers and a purging janitor
+// can share it. Append and PopFront block until there is space or data
+type ConcurrentSlice[T any] struct {
+	mu      sync.Mutex
+	s       *Slice[T]
+	changed chan struct{} // closed and replaced whenever used changes
+}
+
+// NewConcurrentSlice creates a goroutine safe ring. A nil wipe zeroes deleted indices
+func NewConcurrentSlice[T any](capacity int, wipe func(int, []T)) *ConcurrentSlice[T] {
+	return &ConcurrentSlice[T]{
+		s:       NewSlice[T](capacity, false, wipe),
+		changed: make(chan struct{}),
+	}
+}
+
+// Append waits for space then adds value, returns ctx.Err() if cancelled first
+func (c *ConcurrentSlice[T]) Append(ctx context.Context, value T) error {
+	for {
+		c.mu.Lock()
+		if c.s.used < c.s.cap {
+			c.s.Append(value)
+			c.notify()
+			c.mu.Unlock()
+			return nil
+		}
+		wait := c.changed
+		c.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// TryAppend adds value without waiting, returns ErrFull if there is no space
+func (c *ConcurrentSlice[T]) TryAppend(value T) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.s.Append(value); err != nil {
+		return err
+	}
+	c.notify()
+	return nil
+}
+
+// PopFront waits for data then removes and returns the oldest entry
+func (c *ConcurrentSlice[T]) PopFront(ctx context.Context) (T, error) {
+	for {
+		c.mu.Lock()
+		if c.s.used > 0 {
+			v := c.s.popFront()
+			c.notify()
+			c.mu.Unlock()
+			return v, nil
+		}
+		wait := c.changed
+		c.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// DeleteCount deletes count of the oldest values
+func (c *ConcurrentSlice[T]) DeleteCount(count int) []T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l := c.s.deleteCount(count)
+	if len(l) > 0 {
+		c.notify()
+	}
+	return l
+}
+
+// Purge deletes all entries with value <= want, see Slice.Purge
+func (c *ConcurrentSlice[T]) Purge(want int64, value func(T) int64) []T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l := c.s.Purge(want, value)
+	if len(l) > 0 {
+		c.notify()
+	}
+	return l
+}
+
+// Len is the number of entries currently held
+func (c *ConcurrentSlice[T]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.s.used
+}
+
+// notify wakes every waiter, must hold mu
+func (c *ConcurrentSlice[T]) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
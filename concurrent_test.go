@@ -0,0 +1,80 @@
+package ringslice
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrentSliceCancel(t *testing.T) {
+	c := NewConcurrentSlice[int](1, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := c.PopFront(ctx)
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	require.NoError(t, c.Append(ctx, 1))
+	require.Equal(t, ErrFull, c.TryAppend(2))
+	require.Equal(t, context.DeadlineExceeded, c.Append(ctx, 2))
+	require.Equal(t, 1, c.Len())
+}
+
+func TestConcurrentSliceProducerConsumer(t *testing.T) {
+	const producers, each = 4, 1000
+	c := NewConcurrentSlice[int](8, nil)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < each; i++ {
+				if err := c.Append(ctx, p*each+i); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(p)
+	}
+
+	seen := make([]bool, producers*each)
+	for i := 0; i < producers*each; i++ {
+		v, err := c.PopFront(ctx)
+		require.NoError(t, err)
+		require.False(t, seen[v])
+		seen[v] = true
+	}
+	wg.Wait()
+	require.Equal(t, 0, c.Len())
+}
+
+func TestConcurrentSlicePurge(t *testing.T) {
+	c := NewConcurrentSlice[int64](16, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	value := func(i int64) int64 { return i }
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := int64(0); i < 1000; i++ {
+			if err := c.Append(ctx, i); err != nil {
+				return
+			}
+		}
+	}()
+
+	var purged int
+	for purged < 1000 {
+		purged += len(c.Purge(int64(purged+4), value))
+		runtime.Gosched()
+	}
+	cancel()
+	<-done
+	require.Equal(t, 0, c.Len())
+}
@@ -0,0 +1,90 @@
+package ringslice
+
+// PushFront adds value before the oldest entry. When full it follows the FullPolicy,
+// except FullOverwrite drops the newest entry since that is the opposite end
+func (s *Slice[T]) PushFront(value T) error {
+	s.lock()
+	defer s.unlock()
+	if s.used == s.cap {
+		switch s.full {
+		case FullOverwrite:
+			if s.cap == 0 {
+				return ErrFull
+			}
+			s.popBack()
+		case FullBlock:
+			if err := s.waitForRoom(1); err != nil {
+				return err
+			}
+		case FullGrow:
+			s.double()
+		default:
+			return ErrFull
+		}
+	}
+	s.start = s.prev(s.start)
+	s.values[s.start] = value
+	s.used++
+	s.mods++
+	return nil
+}
+
+// PopFront deletes and returns the oldest entry
+func (s *Slice[T]) PopFront() (T, error) {
+	s.lock()
+	defer s.unlock()
+	if s.used == 0 {
+		var zero T
+		return zero, ErrEmpty
+	}
+	return s.popFront(), nil
+}
+
+// PopBack deletes and returns the newest entry
+func (s *Slice[T]) PopBack() (T, error) {
+	s.lock()
+	defer s.unlock()
+	if s.used == 0 {
+		var zero T
+		return zero, ErrEmpty
+	}
+	return s.popBack(), nil
+}
+
+// PeekFront is Front, named for deque callers
+func (s *Slice[T]) PeekFront() (T, error) {
+	return s.Front()
+}
+
+// PeekBack is Back, named for deque callers
+func (s *Slice[T]) PeekBack() (T, error) {
+	return s.Back()
+}
+
+// popFront removes the oldest entry, caller guarantees used > 0
+func (s *Slice[T]) popFront() T {
+	v := s.values[s.start]
+	s.wipe(s.start, s.values)
+	s.start = s.next(s.start)
+	s.used--
+	s.freed()
+	return v
+}
+
+// popBack removes the newest entry, caller guarantees used > 0
+func (s *Slice[T]) popBack() T {
+	ind := s.trueIndex(s.start, s.used-1)
+	v := s.values[ind]
+	s.wipe(ind, s.values)
+	s.used--
+	s.freed()
+	return v
+}
+
+// freed records a delete and wakes any blocked Append
+func (s *Slice[T]) freed() {
+	s.mods++
+	if s.space != nil {
+		s.space.Broadcast()
+	}
+}
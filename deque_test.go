@@ -0,0 +1,61 @@
+package ringslice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeque(t *testing.T) {
+	s := NewSlice[int](3, false, nil)
+	require.NoError(t, s.PushFront(2))
+	require.NoError(t, s.PushFront(1))
+	require.NoError(t, s.Append(3))
+	require.Equal(t, ErrFull, s.PushFront(0))
+	require.Equal(t, []int{3, 1, 2}, s.values) // start walked backwards past 0
+	require.Equal(t, 1, s.start)
+
+	front, err := s.PeekFront()
+	require.NoError(t, err)
+	require.Equal(t, 1, front)
+	back, err := s.PeekBack()
+	require.NoError(t, err)
+	require.Equal(t, 3, back)
+
+	v, err := s.PopBack()
+	require.NoError(t, err)
+	require.Equal(t, 3, v)
+	v, err = s.PopFront()
+	require.NoError(t, err)
+	require.Equal(t, 1, v)
+	v, err = s.PopFront()
+	require.NoError(t, err)
+	require.Equal(t, 2, v)
+	require.Equal(t, []int{0, 0, 0}, s.values)
+
+	_, err = s.PopFront()
+	require.Equal(t, ErrEmpty, err)
+	_, err = s.PopBack()
+	require.Equal(t, ErrEmpty, err)
+}
+
+func TestPushFrontOverwrite(t *testing.T) {
+	s := NewSlice[int](2, false, nil, WithFullPolicy(FullOverwrite))
+	require.NoError(t, s.Append(1))
+	require.NoError(t, s.Append(2))
+	require.NoError(t, s.PushFront(0)) // drops 2
+	got := []int{}
+	for _, v := range s.All() {
+		got = append(got, v)
+	}
+	require.Equal(t, []int{0, 1}, got)
+}
+
+func TestPushFrontGrow(t *testing.T) {
+	s := NewSlice[int](1, false, nil, WithFullPolicy(FullGrow))
+	require.NoError(t, s.Append(1))
+	require.NoError(t, s.PushFront(0))
+	require.Equal(t, 2, s.Cap())
+	a, b := s.Segments()
+	require.Equal(t, []int{0, 1}, append(a, b...))
+}
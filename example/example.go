@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+
+	"github.com/ShookieShookie/ringslice"
 )
 
 func main() {
@@ -12,11 +14,10 @@ func main() {
 	// full
 	fmt.Println("Deleting indices 0 to 3")
 	s.DeleteBounds(0, 3)
-	fmt.Println(s.values)
+	fmt.Println(s.Values(value))
 
-	fmt.Println("new start", s.start)
 	s.Append(25)
-	fmt.Println(s.values)
+	fmt.Println(s.Values(value))
 
 	// same test but with length instead of bounds
 
@@ -24,17 +25,20 @@ func main() {
 
 	s.DeleteCount(4)
 
-	fmt.Println(s.values)
+	fmt.Println(s.Values(value))
 
-	fmt.Println("new start", s.start)
 	s.Append(25)
-	fmt.Println(s.values)
+	fmt.Println(s.Values(value))
+
+}
 
+func value(i int) int64 {
+	return int64(i)
 }
 
-func simpleFive() *Slice {
+func simpleFive() *ringslice.Slice[int] {
 
-	s := NewSlice(5, false)
+	s := ringslice.NewSlice[int](5, false, nil)
 	count := 0
 	for i := 1; i <= 5; i++ {
 		err := s.Append(i)
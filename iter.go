@@ -0,0 +1,50 @@
+package ringslice
+
+import "iter"
+
+// modifiedPanic is raised when the ring is appended to, deleted from or resized mid iteration
+const modifiedPanic = "ringslice: slice modified during iteration"
+
+// All iterates oldest to newest, yielding the logical index and the entry.
+// Set is allowed inside the loop, anything that moves start or used panics
+func (s *Slice[T]) All() iter.Seq2[int, T] {
+	return s.Range(0, -1)
+}
+
+// Backward iterates newest to oldest, yielding the logical index and the entry
+func (s *Slice[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		mods := s.mods
+		for i := s.used - 1; i >= 0; i-- {
+			if !yield(i, s.values[s.trueIndex(s.start, i)]) {
+				return
+			}
+			if s.mods != mods {
+				panic(modifiedPanic)
+			}
+		}
+	}
+}
+
+// Range iterates the logical indices [from, to) oldest to newest. Both are clamped
+// to [0, Len()] and a negative to means Len()
+func (s *Slice[T]) Range(from, to int) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		mods := s.mods
+		end := to
+		if end < 0 || end > s.used {
+			end = s.used
+		}
+		if from < 0 {
+			from = 0
+		}
+		for i := from; i < end; i++ {
+			if !yield(i, s.values[s.trueIndex(s.start, i)]) {
+				return
+			}
+			if s.mods != mods {
+				panic(modifiedPanic)
+			}
+		}
+	}
+}
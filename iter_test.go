@@ -0,0 +1,86 @@
+package ringslice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func wrappedInts() *Slice[int] {
+	s := NewSlice[int](5, false, nil)
+	for i := 0; i < 5; i++ {
+		s.Append(i)
+	}
+	s.DeleteCount(3)
+	for i := 5; i < 8; i++ {
+		s.Append(i)
+	}
+	return s // 3..7 starting at index 3
+}
+
+func TestAll(t *testing.T) {
+	s := wrappedInts()
+	var idx, got []int
+	for i, v := range s.All() {
+		idx = append(idx, i)
+		got = append(got, v)
+	}
+	require.Equal(t, []int{0, 1, 2, 3, 4}, idx)
+	require.Equal(t, []int{3, 4, 5, 6, 7}, got)
+}
+
+func TestBackward(t *testing.T) {
+	s := wrappedInts()
+	var got []int
+	for i, v := range s.Backward() {
+		require.Equal(t, v-3, i)
+		got = append(got, v)
+	}
+	require.Equal(t, []int{7, 6, 5, 4, 3}, got)
+}
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to int
+		want     []int
+	}{
+		{name: "middle", from: 1, to: 4, want: []int{4, 5, 6}},
+		{name: "to end", from: 3, to: -1, want: []int{6, 7}},
+		{name: "clamped", from: -2, to: 100, want: []int{3, 4, 5, 6, 7}},
+		{name: "empty", from: 3, to: 3, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, v := range wrappedInts().Range(tt.from, tt.to) {
+				got = append(got, v)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIterationBreakAndModify(t *testing.T) {
+	s := wrappedInts()
+	for i := range s.All() {
+		if i == 1 {
+			break
+		}
+	}
+	for i, v := range s.All() {
+		require.NoError(t, s.Set(i, v*10)) // Set doesn't move anything
+	}
+	require.Equal(t, 30, s.values[s.start])
+
+	require.PanicsWithValue(t, modifiedPanic, func() {
+		for range s.All() {
+			s.DeleteCount(1)
+		}
+	})
+	require.PanicsWithValue(t, modifiedPanic, func() {
+		for range s.Backward() {
+			s.Append(1)
+		}
+	})
+}
@@ -0,0 +1,247 @@
+package ringslice
+
+import "iter"
+
+// Keyed is a Slice whose entries are ordered by a key given once at construction,
+// typically a timestamp. Lookups binary search the wrapped contents and return
+// logical indices for use with At, Range and DeleteCount. The FindClosest methods
+// inherited from Slice are the exception and return backing array indices
+type Keyed[T any] struct {
+	*Slice[T]
+	key   func(T) int64
+	order OrderPolicy
+	clamp func(T, int64) T
+
+	maxLateness     int64
+	maxDisplacement int
+}
+
+// NewKeyed creates a keyed ring. A nil wipe zeroes deleted indices
+func NewKeyed[T any](capacity int, wipe func(int, []T), key func(T) int64, opts ...Option) *Keyed[T] {
+	c := newConfig(opts)
+	k := &Keyed[T]{
+		Slice:           NewSlice[T](capacity, false, wipe, opts...),
+		key:             key,
+		order:           c.order,
+		maxLateness:     c.maxLateness,
+		maxDisplacement: c.maxDisplacement,
+	}
+	if c.order == OrderClamp {
+		clamp, ok := c.clamp.(func(T, int64) T)
+		if !ok {
+			panic("ringslice: WithClamp element type does not match NewKeyed")
+		}
+		k.clamp = clamp
+	}
+	return k
+}
+
+// Append adds an entry, checking its key against the newest one under WithOrder
+func (k *Keyed[T]) Append(value T) error {
+	_, _, err := k.AppendEvict(value)
+	return err
+}
+
+// AppendEvict is Slice.AppendEvict with the WithOrder check. Set and PushFront are not checked
+func (k *Keyed[T]) AppendEvict(value T) (evicted T, ok bool, err error) {
+	k.lock()
+	defer k.unlock()
+	return k.appendOrdered(value)
+}
+
+// appendOrdered checks the order and appends without dropping the lock in between, so
+// under FullBlock it waits for room before looking at the newest key
+func (k *Keyed[T]) appendOrdered(value T) (evicted T, ok bool, err error) {
+	if k.order == OrderNone {
+		return k.appendEvict(value)
+	}
+	if err = k.waitForRoom(1); err != nil {
+		return evicted, false, err
+	}
+	if k.used > 0 {
+		var keep bool
+		tail := k.key(k.values[k.trueIndex(k.start, k.used-1)])
+		if value, keep, err = k.ordered(value, tail); !keep {
+			return evicted, false, err
+		}
+	}
+	return k.appendEvict(value)
+}
+
+// ordered applies the WithOrder policy to value following an entry keyed tail, keep is
+// false if it must not be appended
+func (k *Keyed[T]) ordered(value T, tail int64) (_ T, keep bool, err error) {
+	if k.order == OrderNone || k.key(value) >= tail {
+		return value, true, nil
+	}
+	switch k.order {
+	case OrderReject:
+		return value, false, ErrOutOfOrder
+	case OrderDrop:
+		return value, false, nil
+	}
+	return k.clamp(value, tail), true, nil
+}
+
+// AppendMany is Slice.AppendMany with the WithOrder check on each value in turn, n counts
+// those dropped by OrderDrop. Under OrderReject it stops at the first out of order value
+// and returns ErrOutOfOrder with how many came before it
+func (k *Keyed[T]) AppendMany(values []T) (int, error) {
+	if k.order == OrderNone {
+		return k.Slice.AppendMany(values)
+	}
+	k.lock()
+	defer k.unlock()
+	for n, v := range values {
+		if _, _, err := k.appendOrdered(v); err != nil {
+			return n, err
+		}
+	}
+	return len(values), nil
+}
+
+// AppendAll is Slice.AppendAll with the WithOrder check, under OrderReject nothing is
+// added if any value is out of order
+func (k *Keyed[T]) AppendAll(values []T) error {
+	k.lock()
+	defer k.unlock()
+	if k.order == OrderNone {
+		return k.appendAll(values)
+	}
+	// wait before checking so appendAll can't drop the lock after
+	if err := k.waitForRoom(len(values)); err != nil {
+		return err
+	}
+	kept := make([]T, 0, len(values))
+	have := k.used > 0
+	var tail int64
+	if have {
+		tail = k.key(k.values[k.trueIndex(k.start, k.used-1)])
+	}
+	for _, v := range values {
+		if have {
+			var keep bool
+			var err error
+			if v, keep, err = k.ordered(v, tail); err != nil {
+				return err
+			}
+			if !keep {
+				continue
+			}
+		}
+		kept = append(kept, v)
+		tail, have = k.key(v), true
+	}
+	return k.appendAll(kept)
+}
+
+// InsertSorted places a late entry after every entry with a key <= its own by shifting the
+// newer entries up one, so searches and Purge stay correct. Returns ErrOutOfOrder without
+// changing anything if that breaks WithMaxLateness or WithMaxDisplacement. When full it
+// follows the FullPolicy, under FullOverwrite the entry is dropped if it would be the oldest
+func (k *Keyed[T]) InsertSorted(value T) error {
+	k.lock()
+	defer k.unlock()
+	// wait before searching, appendEvict must not drop the lock once pos is known
+	if err := k.waitForRoom(1); err != nil {
+		return err
+	}
+	want := k.key(value)
+	pos := k.upperBound(want)
+	shift := k.used - pos
+	if shift > 0 {
+		tail := k.key(k.values[k.trueIndex(k.start, k.used-1)])
+		if k.maxLateness > 0 && tail-want > k.maxLateness {
+			return ErrOutOfOrder
+		}
+		if k.maxDisplacement > 0 && shift > k.maxDisplacement {
+			return ErrOutOfOrder
+		}
+	}
+	if pos == 0 && k.used == k.cap && k.full == FullOverwrite {
+		return nil // it would be the oldest so it is the one evicted
+	}
+	_, evicted, err := k.appendEvict(value)
+	if err != nil {
+		return err
+	}
+	if evicted {
+		pos-- // everything moved down one under the new value
+	}
+	for i := k.used - 1; i > pos; i-- {
+		k.values[k.trueIndex(k.start, i)] = k.values[k.trueIndex(k.start, i-1)]
+	}
+	k.values[k.trueIndex(k.start, pos)] = value
+	return nil
+}
+
+// Key applies the key function
+func (k *Keyed[T]) Key(value T) int64 {
+	return k.key(value)
+}
+
+// LowerBound is the logical index of the first entry with key >= want, Len() if none
+func (k *Keyed[T]) LowerBound(want int64) int {
+	k.lock()
+	defer k.unlock()
+	return k.lowerBound(want)
+}
+
+// UpperBound is the logical index of the first entry with key > want, Len() if none
+func (k *Keyed[T]) UpperBound(want int64) int {
+	k.lock()
+	defer k.unlock()
+	return k.upperBound(want)
+}
+
+// Find returns the logical index of the first entry with key == want
+func (k *Keyed[T]) Find(want int64) (int, bool) {
+	k.lock()
+	defer k.unlock()
+	i := k.lowerBound(want)
+	if i == k.used || k.key(k.values[k.trueIndex(k.start, i)]) != want {
+		return -1, false
+	}
+	return i, true
+}
+
+// RangeBetween returns the entries with lo <= key < hi as at most two slices of
+// the backing array, valid until the next append, delete or resize like Segments
+func (k *Keyed[T]) RangeBetween(lo, hi int64) (a, b []T) {
+	k.lock()
+	defer k.unlock()
+	from, to := k.lowerBound(lo), k.lowerBound(hi)
+	if to < from {
+		to = from
+	}
+	if to == from {
+		return k.values[:0], k.values[:0]
+	}
+	first, last := k.trueIndex(k.start, from), k.trueIndex(k.start, to)
+	if first < last {
+		return k.values[first:last], k.values[:0]
+	}
+	return k.values[first:], k.values[:last]
+}
+
+// Between iterates the entries with lo <= key < hi, oldest to newest
+func (k *Keyed[T]) Between(lo, hi int64) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		k.Range(k.LowerBound(lo), k.LowerBound(hi))(yield)
+	}
+}
+
+// Purge deletes every entry with key <= want, see Slice.Purge
+func (k *Keyed[T]) Purge(want int64) []T {
+	k.lock()
+	defer k.unlock()
+	return k.deleteCount(k.upperBound(want))
+}
+
+func (k *Keyed[T]) lowerBound(want int64) int {
+	return k.search(func(v T) bool { return k.key(v) >= want })
+}
+
+func (k *Keyed[T]) upperBound(want int64) int {
+	return k.search(func(v T) bool { return k.key(v) > want })
+}
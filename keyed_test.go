@@ -0,0 +1,367 @@
+package ringslice
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// keyedWrapped holds 10, 20, 20, 30, 40 starting at backing index 3
+func keyedWrapped() *Keyed[int64] {
+	k := NewKeyed[int64](6, nil, func(v int64) int64 { return v })
+	for i := 0; i < 3; i++ {
+		k.Append(0)
+	}
+	k.DeleteCount(3)
+	for _, v := range []int64{10, 20, 20, 30, 40} {
+		k.Append(v)
+	}
+	return k
+}
+
+func TestKeyedBounds(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    int64
+		lower   int
+		upper   int
+		found   int
+		foundOk bool
+		above   int // FindClosestAboveOrEqual is a backing index, the rest are logical
+	}{
+		{name: "below all", want: 5, lower: 0, upper: 0, found: -1, above: 3},
+		{name: "exact first", want: 10, lower: 0, upper: 1, found: 0, foundOk: true, above: 3},
+		{name: "duplicates", want: 20, lower: 1, upper: 3, found: 1, foundOk: true, above: 4},
+		{name: "between", want: 25, lower: 3, upper: 3, found: -1, above: 0},
+		{name: "exact last", want: 40, lower: 4, upper: 5, found: 4, foundOk: true, above: 1},
+		{name: "above all", want: 50, lower: 5, upper: 5, found: -1, above: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := keyedWrapped()
+			require.Equal(t, tt.lower, k.LowerBound(tt.want))
+			require.Equal(t, tt.upper, k.UpperBound(tt.want))
+			i, ok := k.Find(tt.want)
+			require.Equal(t, tt.found, i)
+			require.Equal(t, tt.foundOk, ok)
+			require.Equal(t, tt.above, k.FindClosestAboveOrEqual(tt.want, k.key))
+		})
+	}
+}
+
+func TestKeyedRangeBetween(t *testing.T) {
+	tests := []struct {
+		name   string
+		lo, hi int64
+		wantA  []int64
+		wantB  []int64
+	}{
+		{name: "all", lo: 0, hi: 100, wantA: []int64{10, 20, 20}, wantB: []int64{30, 40}},
+		{name: "before wrap", lo: 15, hi: 30, wantA: []int64{20, 20}, wantB: []int64{}},
+		{name: "across wrap", lo: 20, hi: 31, wantA: []int64{20, 20}, wantB: []int64{30}},
+		{name: "after wrap", lo: 30, hi: 41, wantA: []int64{30, 40}, wantB: []int64{}},
+		{name: "empty", lo: 21, hi: 29, wantA: []int64{}, wantB: []int64{}},
+		{name: "inverted", lo: 40, hi: 10, wantA: []int64{}, wantB: []int64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := keyedWrapped()
+			a, b := k.RangeBetween(tt.lo, tt.hi)
+			require.Equal(t, tt.wantA, a)
+			require.Equal(t, tt.wantB, b)
+
+			var got []int64
+			for _, v := range k.Between(tt.lo, tt.hi) {
+				got = append(got, v)
+			}
+			require.Equal(t, append(append([]int64{}, tt.wantA...), tt.wantB...), append([]int64{}, got...))
+		})
+	}
+}
+
+func TestKeyedPurge(t *testing.T) {
+	k := keyedWrapped()
+	require.Equal(t, []int64{10, 20, 20}, k.Purge(20))
+	require.Equal(t, 2, k.Len())
+	i, ok := k.Find(30)
+	require.True(t, ok)
+	require.Equal(t, 0, i)
+
+	// a full wrapped ring of equal keys
+	k = NewKeyed[int64](3, nil, func(v int64) int64 { return v }, WithFullPolicy(FullOverwrite))
+	for _, v := range []int64{1, 1, 4, 4, 4} {
+		k.Append(v)
+	}
+	require.Equal(t, 2, k.start)
+	require.Empty(t, k.Purge(3))
+	require.Equal(t, []int64{4, 4, 4}, k.Purge(4))
+}
+
+func TestKeyedEmptyCapacity(t *testing.T) {
+	k := NewKeyed[int64](0, nil, func(v int64) int64 { return v })
+	a, b := k.RangeBetween(0, 10)
+	require.Empty(t, a)
+	require.Empty(t, b)
+	require.Empty(t, k.Purge(10))
+}
+
+type event struct {
+	at   int64
+	name string
+}
+
+func TestKeyedOrder(t *testing.T) {
+	key := func(e event) int64 { return e.at }
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr error
+		want    []event
+	}{
+		{
+			name: "none",
+			want: []event{{10, "a"}, {20, "b"}, {15, "late"}, {30, "c"}},
+		},
+		{
+			name:    "reject",
+			opts:    []Option{WithOrder(OrderReject)},
+			wantErr: ErrOutOfOrder,
+			want:    []event{{10, "a"}, {20, "b"}, {30, "c"}},
+		},
+		{
+			name: "drop",
+			opts: []Option{WithOrder(OrderDrop)},
+			want: []event{{10, "a"}, {20, "b"}, {30, "c"}},
+		},
+		{
+			name: "clamp",
+			opts: []Option{WithClamp(func(e event, at int64) event {
+				e.at = at
+				return e
+			})},
+			want: []event{{10, "a"}, {20, "b"}, {20, "late"}, {30, "c"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := NewKeyed[event](4, nil, key, tt.opts...)
+			require.NoError(t, k.Append(event{10, "a"}))
+			require.NoError(t, k.Append(event{20, "b"}))
+			require.Equal(t, tt.wantErr, k.Append(event{15, "late"}))
+			require.NoError(t, k.Append(event{30, "c"}))
+			var got []event
+			for _, e := range k.All() {
+				got = append(got, e)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestKeyedOrderBlocking(t *testing.T) {
+	key := func(v int64) int64 { return v }
+	for i := 0; i < 20; i++ {
+		k := NewKeyed[int64](2, nil, key, WithFullPolicy(FullBlock), WithOrder(OrderReject))
+		k.Append(10)
+		k.Append(20)
+
+		// 25 waits for room, if 30 gets in first it must be checked against that
+		done := make(chan error)
+		go func() {
+			done <- k.Append(25)
+		}()
+		time.Sleep(time.Millisecond)
+		k.DeleteCount(2)
+		require.NoError(t, k.Append(30))
+		if err := <-done; err != nil {
+			require.Equal(t, ErrOutOfOrder, err)
+		}
+		a, b := k.Segments()
+		got := append(a, b...)
+		require.True(t, sort.SliceIsSorted(got, func(i, j int) bool { return got[i] < got[j] }), got)
+	}
+}
+
+func TestKeyedAppendBatch(t *testing.T) {
+	key := func(v int64) int64 { return v }
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantN   int
+		wantErr error
+		want    []int64
+	}{
+		{name: "none", wantN: 4, want: []int64{10, 5, 15, 12, 20}},
+		{name: "reject", opts: []Option{WithOrder(OrderReject)}, wantErr: ErrOutOfOrder, want: []int64{10}},
+		{name: "drop", opts: []Option{WithOrder(OrderDrop)}, wantN: 4, want: []int64{10, 15, 20}},
+		{
+			name:  "clamp",
+			opts:  []Option{WithClamp(func(v, at int64) int64 { return at })},
+			wantN: 4,
+			want:  []int64{10, 10, 15, 15, 20},
+		},
+		{
+			name:  "block",
+			opts:  []Option{WithOrder(OrderDrop), WithFullPolicy(FullBlock)},
+			wantN: 4,
+			want:  []int64{10, 15, 20},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := NewKeyed[int64](8, nil, key, tt.opts...)
+			k.Append(10)
+			n, err := k.AppendMany([]int64{5, 15, 12, 20})
+			require.Equal(t, tt.wantErr, err)
+			require.Equal(t, tt.wantN, n)
+			a, b := k.Segments()
+			require.Equal(t, tt.want, append(a, b...))
+
+			k = NewKeyed[int64](8, nil, key, tt.opts...)
+			k.Append(10)
+			require.Equal(t, tt.wantErr, k.AppendAll([]int64{5, 15, 12, 20}))
+			a, b = k.Segments()
+			require.Equal(t, tt.want, append(a, b...))
+		})
+	}
+
+	// reject stops at the first bad value, AppendAll adds none
+	k := NewKeyed[int64](8, nil, key, WithOrder(OrderReject))
+	n, err := k.AppendMany([]int64{10, 20, 15, 30})
+	require.Equal(t, ErrOutOfOrder, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, ErrOutOfOrder, k.AppendAll([]int64{25, 30, 28}))
+	require.Equal(t, 2, k.Len())
+}
+
+func TestKeyedClampTypeMismatch(t *testing.T) {
+	require.Panics(t, func() {
+		NewKeyed[int64](1, nil, func(v int64) int64 { return v }, WithClamp(func(v int, at int64) int { return v }))
+	})
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []int64
+		wantErr error
+	}{
+		{name: "in order", input: []int64{1, 2, 2, 4}},
+		{name: "negative", input: []int64{-5, -3, -3, 0}},
+		{name: "middle", input: []int64{1, 3, 2, 4}, wantErr: ErrOutOfOrder},
+		{name: "newest", input: []int64{1, 2, 3, 0}, wantErr: ErrOutOfOrder},
+		{name: "wrapped", input: []int64{1, 2, 3, 4, 5, 6}},
+		{name: "wrapped newest", input: []int64{1, 2, 3, 4, 5, 3}, wantErr: ErrOutOfOrder},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := NewKeyed[int64](4, nil, func(v int64) int64 { return v }, WithFullPolicy(FullOverwrite))
+			for _, v := range tt.input {
+				k.Append(v)
+			}
+			require.Equal(t, tt.wantErr, k.validate(k.key))
+		})
+	}
+}
+
+func TestInsertSorted(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		insert  int64
+		wantErr error
+		want    []int64
+	}{
+		{name: "in order", insert: 50, want: []int64{20, 20, 30, 40, 50}},
+		{name: "equal goes after", insert: 20, want: []int64{20, 20, 20, 30, 40}},
+		{name: "middle", insert: 25, want: []int64{20, 20, 25, 30, 40}},
+		{name: "oldest", insert: 5, want: []int64{5, 20, 20, 30, 40}},
+		{
+			name:    "too late",
+			opts:    []Option{WithMaxLateness(10)},
+			insert:  25,
+			wantErr: ErrOutOfOrder,
+			want:    []int64{20, 20, 30, 40},
+		},
+		{
+			name:   "late enough",
+			opts:   []Option{WithMaxLateness(15)},
+			insert: 25,
+			want:   []int64{20, 20, 25, 30, 40},
+		},
+		{
+			name:   "displaced within bound",
+			opts:   []Option{WithMaxDisplacement(1)},
+			insert: 35,
+			want:   []int64{20, 20, 30, 35, 40},
+		},
+		{
+			name:    "displaced too far",
+			opts:    []Option{WithMaxDisplacement(1)},
+			insert:  25,
+			wantErr: ErrOutOfOrder,
+			want:    []int64{20, 20, 30, 40},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 20, 20, 30, 40 wrapping in a ring of 5
+			k := NewKeyed[int64](5, nil, func(v int64) int64 { return v }, tt.opts...)
+			for _, v := range []int64{0, 0, 0, 20, 20} {
+				k.Append(v)
+			}
+			k.DeleteCount(3)
+			k.Append(30)
+			k.Append(40)
+
+			require.Equal(t, tt.wantErr, k.InsertSorted(tt.insert))
+			var got []int64
+			for _, v := range k.All() {
+				got = append(got, v)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestInsertSortedFull(t *testing.T) {
+	key := func(v int64) int64 { return v }
+	k := NewKeyed[int64](3, nil, key)
+	for _, v := range []int64{10, 20, 30} {
+		k.Append(v)
+	}
+	require.Equal(t, ErrFull, k.InsertSorted(15))
+
+	k = NewKeyed[int64](3, nil, key, WithFullPolicy(FullOverwrite))
+	for _, v := range []int64{10, 20, 30} {
+		k.Append(v)
+	}
+	require.NoError(t, k.InsertSorted(25))
+	require.NoError(t, k.InsertSorted(5)) // older than everything, evicts itself
+	a, b := k.Segments()
+	require.Equal(t, []int64{20, 25, 30}, append(a, b...))
+	require.Equal(t, []int64{20}, k.Purge(24))
+}
+
+func TestInsertSortedBlocking(t *testing.T) {
+	key := func(v int64) int64 { return v }
+	for i := 0; i < 20; i++ {
+		k := NewKeyed[int64](3, nil, key, WithFullPolicy(FullBlock))
+		for _, v := range []int64{10, 20, 30} {
+			k.Append(v)
+		}
+
+		// 25 waits for room while the entries it was placed between go away
+		done := make(chan error)
+		go func() {
+			done <- k.InsertSorted(25)
+		}()
+		time.Sleep(time.Millisecond)
+		k.DeleteCount(2)
+		require.NoError(t, <-done)
+		a, b := k.Segments()
+		require.Equal(t, []int64{25, 30}, append(a, b...))
+	}
+}
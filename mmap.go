@@ -0,0 +1,218 @@
+//go:build linux || darwin || freebsd || openbsd
+
+package ringslice
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// MmapRing keeps fixed size records in a memory mapped file so a process can reopen
+// it after a restart and carry on. The file is two header slots followed by cap
+// records. Each change writes the records first, then a header with the new start and
+// used into the older slot with a higher sequence number and a CRC, so a torn or
+// corrupt header write leaves the previous header to fall back on. Writes survive
+// the process dying once made, Sync is needed for them to survive the machine dying
+type MmapRing struct {
+	file       *os.File
+	data       []byte // the whole mapping
+	start      int
+	used       int
+	cap        int
+	recordSize int
+	seq        uint64
+	closed     bool
+}
+
+var mmapMagic = []byte("RINGSLC1")
+
+const (
+	mmapSlotSize   = 64
+	mmapHeaderSize = 2 * mmapSlotSize
+	mmapCRCOffset  = 48 // magic, seq, cap, recordSize, start, used then the crc
+)
+
+// OpenMmapRing opens the ring at path, creating it if it doesn't exist or is empty.
+// An existing file must have been made with the same capacity and recordSize
+func OpenMmapRing(path string, capacity, recordSize int) (*MmapRing, error) {
+	if capacity <= 0 || recordSize <= 0 {
+		return nil, fmt.Errorf("ringslice: capacity and record size must be positive")
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	size := mmapHeaderSize + capacity*recordSize
+	fresh := info.Size() == 0
+	if fresh {
+		if err := f.Truncate(int64(size)); err != nil {
+			f.Close()
+			return nil, err
+		}
+	} else if info.Size() != int64(size) {
+		f.Close()
+		return nil, ErrCorrupt
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	m := &MmapRing{file: f, data: data, cap: capacity, recordSize: recordSize}
+	if fresh {
+		m.writeHeader()
+		return m, nil
+	}
+	if err := m.readHeader(); err != nil {
+		m.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+// Append adds a record, which must be exactly the record size, returns ErrFull if full
+func (m *MmapRing) Append(record []byte) error {
+	if m.closed {
+		return os.ErrClosed
+	}
+	if len(record) != m.recordSize {
+		return ErrRecordSize
+	}
+	if m.used == m.cap {
+		return ErrFull
+	}
+	copy(m.record(m.trueIndex(m.start, m.used)), record)
+	m.used++
+	m.writeHeader()
+	return nil
+}
+
+// DeleteCount deletes count of the oldest records and returns copies of them
+func (m *MmapRing) DeleteCount(count int) ([][]byte, error) {
+	if m.closed {
+		return nil, os.ErrClosed
+	}
+	if count > m.used {
+		count = m.used // save us some time
+	}
+	l := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		l = append(l, append([]byte(nil), m.record(m.trueIndex(m.start, i))...))
+	}
+	m.start = m.trueIndex(m.start, count)
+	m.used -= count
+	m.writeHeader()
+	return l, nil
+}
+
+// At returns a copy of the record i away from the oldest
+func (m *MmapRing) At(i int) ([]byte, error) {
+	if m.closed {
+		return nil, os.ErrClosed
+	}
+	if i < 0 || i >= m.used {
+		return nil, ErrOutOfRange
+	}
+	return append([]byte(nil), m.record(m.trueIndex(m.start, i))...), nil
+}
+
+// Len is the number of records held
+func (m *MmapRing) Len() int {
+	return m.used
+}
+
+// Cap is the number of records that fit
+func (m *MmapRing) Cap() int {
+	return m.cap
+}
+
+// Sync flushes the mapping to disk with msync, since fsync alone isn't guaranteed to
+// write back MAP_SHARED pages outside linux, then fsyncs the file's metadata
+func (m *MmapRing) Sync() error {
+	if m.closed {
+		return os.ErrClosed
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_MSYNC, uintptr(unsafe.Pointer(&m.data[0])), uintptr(len(m.data)), syscall.MS_SYNC)
+	if errno != 0 {
+		return errno
+	}
+	return m.file.Sync()
+}
+
+// Close unmaps and closes the file without syncing, closing again does nothing.
+// Everything but Len and Cap returns os.ErrClosed afterwards
+func (m *MmapRing) Close() error {
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+	err := syscall.Munmap(m.data)
+	m.data = nil
+	if cerr := m.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (m *MmapRing) record(ind int) []byte {
+	off := mmapHeaderSize + ind*m.recordSize
+	return m.data[off : off+m.recordSize]
+}
+
+func (m *MmapRing) trueIndex(start, length int) int {
+	return (start + length) % m.cap
+}
+
+// writeHeader publishes start and used into the slot not holding the current header
+func (m *MmapRing) writeHeader() {
+	m.seq++
+	slot := m.data[(m.seq%2)*mmapSlotSize:][:mmapSlotSize]
+	copy(slot, mmapMagic)
+	binary.LittleEndian.PutUint64(slot[8:], m.seq)
+	binary.LittleEndian.PutUint64(slot[16:], uint64(m.cap))
+	binary.LittleEndian.PutUint64(slot[24:], uint64(m.recordSize))
+	binary.LittleEndian.PutUint64(slot[32:], uint64(m.start))
+	binary.LittleEndian.PutUint64(slot[40:], uint64(m.used))
+	binary.LittleEndian.PutUint32(slot[mmapCRCOffset:], crc32.ChecksumIEEE(slot[:mmapCRCOffset]))
+}
+
+// readHeader loads the valid slot with the highest sequence number
+func (m *MmapRing) readHeader() error {
+	found := false
+	for i := 0; i < 2; i++ {
+		slot := m.data[i*mmapSlotSize:][:mmapSlotSize]
+		if !bytes.Equal(slot[:8], mmapMagic) {
+			continue
+		}
+		if crc32.ChecksumIEEE(slot[:mmapCRCOffset]) != binary.LittleEndian.Uint32(slot[mmapCRCOffset:]) {
+			continue
+		}
+		seq := binary.LittleEndian.Uint64(slot[8:])
+		capacity := binary.LittleEndian.Uint64(slot[16:])
+		recordSize := binary.LittleEndian.Uint64(slot[24:])
+		start := binary.LittleEndian.Uint64(slot[32:])
+		used := binary.LittleEndian.Uint64(slot[40:])
+		if capacity != uint64(m.cap) || recordSize != uint64(m.recordSize) || start >= capacity || used > capacity {
+			continue
+		}
+		if found && seq <= m.seq {
+			continue
+		}
+		found = true
+		m.seq, m.start, m.used = seq, int(start), int(used)
+	}
+	if !found {
+		return ErrCorrupt
+	}
+	return nil
+}
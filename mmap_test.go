@@ -0,0 +1,151 @@
+//go:build linux || darwin || freebsd || openbsd
+
+package ringslice
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func record(c byte) []byte {
+	return []byte{c, c, c, c}
+}
+
+// filledMmapRing leaves "b", "c", "d" wrapped in a ring of 3 at path, closed
+func filledMmapRing(t *testing.T, path string) {
+	m, err := OpenMmapRing(path, 3, 4)
+	require.NoError(t, err)
+	for _, c := range []byte("abc") {
+		require.NoError(t, m.Append(record(c)))
+	}
+	got, err := m.DeleteCount(1)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{record('a')}, got)
+	require.NoError(t, m.Append(record('d')))
+	require.NoError(t, m.Sync())
+	require.NoError(t, m.Close())
+}
+
+func mmapContents(t *testing.T, m *MmapRing) string {
+	var got []byte
+	for i := 0; i < m.Len(); i++ {
+		r, err := m.At(i)
+		require.NoError(t, err)
+		got = append(got, r[0])
+	}
+	return string(got)
+}
+
+func TestMmapRingReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+	filledMmapRing(t, path)
+
+	m, err := OpenMmapRing(path, 3, 4)
+	require.NoError(t, err)
+	defer m.Close()
+	require.Equal(t, "bcd", mmapContents(t, m))
+	require.Equal(t, ErrFull, m.Append(record('e')))
+	require.Equal(t, ErrRecordSize, m.Append([]byte("toolong")))
+	_, err = m.At(3)
+	require.Equal(t, ErrOutOfRange, err)
+
+	got, err := m.DeleteCount(2)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{record('b'), record('c')}, got)
+	require.NoError(t, m.Append(record('e')))
+	require.Equal(t, "de", mmapContents(t, m))
+}
+
+func TestMmapRingCrash(t *testing.T) {
+	tests := []struct {
+		name    string
+		damage  func(t *testing.T, path string)
+		want    string
+		wantErr error
+	}{
+		{
+			name: "newest header corrupt falls back one change",
+			damage: func(t *testing.T, path string) {
+				// creating plus 5 changes leaves the newest header in slot 0
+				corruptAt(t, path, 33)
+			},
+			want: "bc",
+		},
+		{
+			name: "older header corrupt is ignored",
+			damage: func(t *testing.T, path string) {
+				corruptAt(t, path, mmapSlotSize+8)
+			},
+			want: "bcd",
+		},
+		{
+			name: "both headers corrupt",
+			damage: func(t *testing.T, path string) {
+				corruptAt(t, path, 0)
+				corruptAt(t, path, mmapSlotSize)
+			},
+			wantErr: ErrCorrupt,
+		},
+		{
+			name: "truncated",
+			damage: func(t *testing.T, path string) {
+				require.NoError(t, os.Truncate(path, mmapHeaderSize+4))
+			},
+			wantErr: ErrCorrupt,
+		},
+		{
+			name: "truncated into the header",
+			damage: func(t *testing.T, path string) {
+				require.NoError(t, os.Truncate(path, 10))
+			},
+			wantErr: ErrCorrupt,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "ring")
+			filledMmapRing(t, path)
+			tt.damage(t, path)
+
+			m, err := OpenMmapRing(path, 3, 4)
+			require.Equal(t, tt.wantErr, err)
+			if err != nil {
+				return
+			}
+			defer m.Close()
+			require.Equal(t, tt.want, mmapContents(t, m))
+			// and it carries on from there
+			_, err = m.DeleteCount(1)
+			require.NoError(t, err)
+			require.NoError(t, m.Append(record('z')))
+		})
+	}
+}
+
+func TestMmapRingClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+	filledMmapRing(t, path)
+	m, err := OpenMmapRing(path, 3, 4)
+	require.NoError(t, err)
+	require.NoError(t, m.Close())
+	require.NoError(t, m.Close())
+
+	require.Equal(t, os.ErrClosed, m.Append(record('e')))
+	_, err = m.At(0)
+	require.Equal(t, os.ErrClosed, err)
+	_, err = m.DeleteCount(1)
+	require.Equal(t, os.ErrClosed, err)
+	require.Equal(t, os.ErrClosed, m.Sync())
+}
+
+func TestMmapRingMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+	filledMmapRing(t, path)
+	_, err := OpenMmapRing(path, 4, 3) // same file size, different layout
+	require.Equal(t, ErrCorrupt, err)
+	_, err = OpenMmapRing(path, 0, 4)
+	require.Error(t, err)
+}
@@ -0,0 +1,112 @@
+package ringslice
+
+import "sync/atomic"
+
+// MPMC is a bounded lock free ring safe for any number of producers and consumers.
+// Each slot carries a sequence number (Vyukov's bounded queue) so claiming a slot is a
+// single CAS on the shared counter. Append and DeleteCount match Slice so it can stand in
+// for one, but there is no Purge since a search can't be done atomically
+type MPMC[T any] struct {
+	slots []mpmcSlot[T]
+	mask  uint64
+	_     [cacheLine]byte
+	head  uint64 // next position to delete
+	_     [cacheLine - 8]byte
+	tail  uint64 // next position to append
+	_     [cacheLine - 8]byte
+}
+
+type mpmcSlot[T any] struct {
+	seq   uint64
+	value T
+}
+
+// NewMPMC creates a ring holding at least capacity entries, rounded up to a power of two
+func NewMPMC[T any](capacity int) *MPMC[T] {
+	c := nextPowerOfTwo(capacity)
+	if c < 2 {
+		c = 2 // a single slot can't tell full from empty by sequence alone
+	}
+	r := &MPMC[T]{slots: make([]mpmcSlot[T], c), mask: uint64(c - 1)}
+	for i := range r.slots {
+		r.slots[i].seq = uint64(i)
+	}
+	return r
+}
+
+// Append adds an entry if possible, returns ErrFull if full
+func (r *MPMC[T]) Append(value T) error {
+	pos := atomic.LoadUint64(&r.tail)
+	for {
+		slot := &r.slots[pos&r.mask]
+		seq := atomic.LoadUint64(&slot.seq)
+		switch dif := int64(seq - pos); {
+		case dif == 0:
+			if atomic.CompareAndSwapUint64(&r.tail, pos, pos+1) {
+				slot.value = value
+				atomic.StoreUint64(&slot.seq, pos+1)
+				return nil
+			}
+			pos = atomic.LoadUint64(&r.tail)
+		case dif < 0:
+			return ErrFull // slot still holds a value from the previous lap
+		default:
+			pos = atomic.LoadUint64(&r.tail) // another producer took it
+		}
+	}
+}
+
+// TryPop removes the oldest entry if there is one
+func (r *MPMC[T]) TryPop() (T, bool) {
+	var zero T
+	pos := atomic.LoadUint64(&r.head)
+	for {
+		slot := &r.slots[pos&r.mask]
+		seq := atomic.LoadUint64(&slot.seq)
+		switch dif := int64(seq - (pos + 1)); {
+		case dif == 0:
+			if atomic.CompareAndSwapUint64(&r.head, pos, pos+1) {
+				v := slot.value
+				slot.value = zero
+				atomic.StoreUint64(&slot.seq, pos+r.mask+1)
+				return v, true
+			}
+			pos = atomic.LoadUint64(&r.head)
+		case dif < 0:
+			return zero, false
+		default:
+			pos = atomic.LoadUint64(&r.head)
+		}
+	}
+}
+
+// DeleteCount deletes up to count of the oldest values and returns them
+func (r *MPMC[T]) DeleteCount(count int) []T {
+	if count > len(r.slots) {
+		count = len(r.slots) // save us some time
+	}
+	l := make([]T, 0, count)
+	for i := 0; i < count; i++ {
+		v, ok := r.TryPop()
+		if !ok {
+			break
+		}
+		l = append(l, v)
+	}
+	return l
+}
+
+// Len is a snapshot of the number of entries, may be stale by the time it returns
+func (r *MPMC[T]) Len() int {
+	head := atomic.LoadUint64(&r.head)
+	tail := atomic.LoadUint64(&r.tail)
+	if tail < head {
+		return 0
+	}
+	return int(tail - head)
+}
+
+// Cap is the rounded up capacity
+func (r *MPMC[T]) Cap() int {
+	return len(r.slots)
+}
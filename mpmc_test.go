@@ -0,0 +1,89 @@
+package ringslice
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMPMCSequential(t *testing.T) {
+	r := NewMPMC[int](1)
+	require.Equal(t, 2, r.Cap())
+	require.NoError(t, r.Append(1))
+	require.NoError(t, r.Append(2))
+	require.Equal(t, ErrFull, r.Append(3))
+	require.Equal(t, []int{1}, r.DeleteCount(1))
+	require.NoError(t, r.Append(3))
+	require.Equal(t, 2, r.Len())
+	require.Equal(t, []int{2, 3}, r.DeleteCount(100))
+	require.Equal(t, []int{}, r.DeleteCount(1))
+	_, ok := r.TryPop()
+	require.False(t, ok)
+}
+
+func TestMPMCStress(t *testing.T) {
+	const producers, consumers, each = 4, 4, 20000
+	r := NewMPMC[int](32)
+
+	var pwg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		pwg.Add(1)
+		go func(p int) {
+			defer pwg.Done()
+			for i := 0; i < each; {
+				if r.Append(p*each+i) == nil {
+					i++
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}(p)
+	}
+
+	var mu sync.Mutex
+	seen := make([]int, producers*each)
+	var cwg sync.WaitGroup
+	remaining := int64(producers * each)
+	for c := 0; c < consumers; c++ {
+		cwg.Add(1)
+		go func() {
+			defer cwg.Done()
+			last := make([]int, producers) // per producer order must be kept
+			for i := range last {
+				last[i] = -1
+			}
+			for {
+				mu.Lock()
+				if remaining == 0 {
+					mu.Unlock()
+					return
+				}
+				mu.Unlock()
+				vs := r.DeleteCount(4)
+				if len(vs) == 0 {
+					runtime.Gosched()
+					continue
+				}
+				mu.Lock()
+				for _, v := range vs {
+					seen[v]++
+					remaining--
+					p := v / each
+					if v <= last[p] {
+						t.Errorf("producer %d out of order %d after %d", p, v, last[p])
+					}
+					last[p] = v
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	pwg.Wait()
+	cwg.Wait()
+	for v, n := range seen {
+		require.Equal(t, 1, n, "value %d", v)
+	}
+	require.Equal(t, 0, r.Len())
+}
@@ -0,0 +1,167 @@
+package ringslice
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrFull is returned by Append when the ring is full and the policy rejects
+	ErrFull = errors.New("Index is full cannot append")
+	// ErrTooSmall is returned by Resize when the new capacity can't hold what is used
+	ErrTooSmall = errors.New("capacity is smaller than used")
+	// ErrOutOfRange is returned when a logical index is not in [0, Len())
+	ErrOutOfRange = errors.New("index out of range")
+	// ErrEmpty is returned when reading the ends of an empty ring
+	ErrEmpty = errors.New("ring is empty")
+	// ErrOutOfOrder is returned by an ordered Keyed Append when the key is below the tail key
+	ErrOutOfOrder = errors.New("values out of order")
+	// ErrExpired is returned by BucketRing.Add for a time older than the oldest bucket
+	ErrExpired = errors.New("time is older than the oldest bucket")
+	// ErrRecordSize is returned when a record doesn't match a fixed record size
+	ErrRecordSize = errors.New("record is not the ring's record size")
+	// ErrCorrupt is returned when opening a persisted ring whose header or size doesn't check out
+	ErrCorrupt = errors.New("ring file is corrupt")
+)
+
+// FullPolicy decides what Append does once used == cap
+type FullPolicy int
+
+const (
+	// FullReject returns ErrFull, the original behaviour
+	FullReject FullPolicy = iota
+	// FullOverwrite evicts the oldest entry to make room
+	FullOverwrite
+	// FullBlock waits until another goroutine deletes something
+	FullBlock
+	// FullGrow doubles the capacity
+	FullGrow
+)
+
+// OrderPolicy decides what a Keyed Append does with a key lower than the newest key
+type OrderPolicy int
+
+const (
+	// OrderNone appends anything, searches are undefined if keys go backwards
+	OrderNone OrderPolicy = iota
+	// OrderReject returns ErrOutOfOrder
+	OrderReject
+	// OrderDrop silently discards the entry
+	OrderDrop
+	// OrderClamp raises the key to the newest key using the function from WithClamp
+	OrderClamp
+)
+
+// SyncPolicy decides when a WAL fsyncs its writes
+type SyncPolicy int
+
+const (
+	// SyncEveryWrite fsyncs before Append and DeleteCount return
+	SyncEveryWrite SyncPolicy = iota
+	// SyncInterval fsyncs anything outstanding every interval, see WithSyncInterval
+	SyncInterval
+	// SyncNever leaves it to the OS, or explicit calls to Sync
+	SyncNever
+)
+
+// Option configures a ring at construction
+type Option func(*config)
+
+type config struct {
+	full  FullPolicy
+	order OrderPolicy
+	clamp interface{} // func(T, int64) T, typed by WithClamp
+
+	maxLateness     int64
+	maxDisplacement int
+
+	clock Clock
+
+	sketchAccuracy float64
+
+	sync         SyncPolicy
+	syncInterval time.Duration
+
+	codec interface{} // Codec[T], typed by WithCodec
+}
+
+func newConfig(opts []Option) config {
+	c := config{}
+	for _, o := range opts {
+		o(&c)
+	}
+	return c
+}
+
+// WithFullPolicy sets what happens on Append to a full ring
+func WithFullPolicy(p FullPolicy) Option {
+	return func(c *config) {
+		c.full = p
+	}
+}
+
+// WithOrder makes a Keyed ring enforce non decreasing keys on Append
+func WithOrder(p OrderPolicy) Option {
+	return func(c *config) {
+		c.order = p
+	}
+}
+
+// WithClamp selects OrderClamp, set must return value with its key replaced by key
+func WithClamp[T any](set func(value T, key int64) T) Option {
+	return func(c *config) {
+		c.order = OrderClamp
+		c.clamp = set
+	}
+}
+
+// WithMaxLateness bounds Keyed.InsertSorted to keys at most d below the newest key, 0 is unbounded
+func WithMaxLateness(d int64) Option {
+	return func(c *config) {
+		c.maxLateness = d
+	}
+}
+
+// WithMaxDisplacement bounds Keyed.InsertSorted to shifting at most n entries, 0 is unbounded
+func WithMaxDisplacement(n int) Option {
+	return func(c *config) {
+		c.maxDisplacement = n
+	}
+}
+
+// WithClock replaces RealClock for time based rings, mainly for tests
+func WithClock(clock Clock) Option {
+	return func(c *config) {
+		c.clock = clock
+	}
+}
+
+// WithSketch makes a QuantileWindow estimate with a sketch instead of keeping every value
+// sorted. Answers are within relativeAccuracy of the true value, e.g. 0.01 for 1%
+func WithSketch(relativeAccuracy float64) Option {
+	return func(c *config) {
+		c.sketchAccuracy = relativeAccuracy
+	}
+}
+
+// WithSync sets when a WAL fsyncs, SyncInterval needs WithSyncInterval instead
+func WithSync(p SyncPolicy) Option {
+	return func(c *config) {
+		c.sync = p
+	}
+}
+
+// WithSyncInterval makes a WAL fsync outstanding writes every d
+func WithSyncInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.sync = SyncInterval
+		c.syncInterval = d
+	}
+}
+
+// WithCodec sets how a Slice encodes its elements for MarshalBinary and gob, GobCodec by default
+func WithCodec[T any](codec Codec[T]) Option {
+	return func(c *config) {
+		c.codec = codec
+	}
+}
@@ -0,0 +1,280 @@
+package ringslice
+
+import (
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// PipeConn is one end of a buffered in memory full duplex connection made by Pipe.
+// Like net.Pipe, but each direction is a ByteRing of fixed capacity so a Write only
+// blocks once the ring is full and a Read only blocks while it is empty
+type PipeConn struct {
+	rd    *pipeHalf // the direction we read from
+	wr    *pipeHalf // the direction we write to
+	clock Clock
+
+	readDeadline  *pipeDeadline
+	writeDeadline *pipeDeadline
+
+	once sync.Once
+	done chan struct{} // closed by our own Close
+}
+
+// pipeHalf is one direction of the pipe
+type pipeHalf struct {
+	mu      sync.Mutex
+	ring    *ByteRing
+	changed chan struct{} // closed and replaced whenever the ring or errors change
+	rerr    error         // given to the reader once drained, set when the writer closes
+	werr    error         // given to the writer, set when the reader closes
+}
+
+// Pipe creates a connected pair, each direction buffering capacity bytes.
+// WithClock replaces RealClock for deadlines
+func Pipe(capacity int, opts ...Option) (*PipeConn, *PipeConn) {
+	c := newConfig(opts)
+	clock := c.clock
+	if clock == nil {
+		clock = RealClock
+	}
+	ab := newPipeHalf(capacity)
+	ba := newPipeHalf(capacity)
+	return newPipeConn(ba, ab, clock), newPipeConn(ab, ba, clock)
+}
+
+func newPipeHalf(capacity int) *pipeHalf {
+	return &pipeHalf{ring: NewByteRing(capacity), changed: make(chan struct{})}
+}
+
+func newPipeConn(rd, wr *pipeHalf, clock Clock) *PipeConn {
+	return &PipeConn{
+		rd:            rd,
+		wr:            wr,
+		clock:         clock,
+		readDeadline:  newPipeDeadline(clock),
+		writeDeadline: newPipeDeadline(clock),
+		done:          make(chan struct{}),
+	}
+}
+
+// Read reads buffered data, blocking while there is none. Once the other end closes
+// the remaining data is still read before io.EOF, or the error given to CloseWithError
+func (c *PipeConn) Read(p []byte) (int, error) {
+	for {
+		switch {
+		case isClosed(c.done):
+			return 0, io.ErrClosedPipe
+		case isClosed(c.readDeadline.wait()):
+			return 0, os.ErrDeadlineExceeded
+		}
+		h := c.rd
+		h.mu.Lock()
+		if h.ring.Len() > 0 {
+			n, _ := h.ring.Read(p)
+			h.notify()
+			h.mu.Unlock()
+			return n, nil
+		}
+		if h.rerr != nil {
+			err := h.rerr
+			h.mu.Unlock()
+			return 0, err
+		}
+		if len(p) == 0 {
+			h.mu.Unlock()
+			return 0, nil
+		}
+		changed := h.changed
+		h.mu.Unlock()
+		select {
+		case <-changed:
+		case <-c.readDeadline.wait():
+		case <-c.done:
+		}
+	}
+}
+
+// Write writes all of p, blocking whenever the ring is full
+func (c *PipeConn) Write(p []byte) (int, error) {
+	n := 0
+	for {
+		switch {
+		case isClosed(c.done):
+			return n, io.ErrClosedPipe
+		case isClosed(c.writeDeadline.wait()):
+			return n, os.ErrDeadlineExceeded
+		}
+		h := c.wr
+		h.mu.Lock()
+		if h.werr != nil {
+			err := h.werr
+			h.mu.Unlock()
+			return n, err
+		}
+		if w := h.ring.copyIn(p[n:]); w > 0 {
+			n += w
+			h.notify()
+		}
+		if n == len(p) {
+			h.mu.Unlock()
+			return n, nil
+		}
+		changed := h.changed
+		h.mu.Unlock()
+		select {
+		case <-changed:
+		case <-c.writeDeadline.wait():
+		case <-c.done:
+		}
+	}
+}
+
+// Close is CloseWithError(nil)
+func (c *PipeConn) Close() error {
+	return c.CloseWithError(nil)
+}
+
+// CloseWithError closes this end. Our own pending and future Reads and Writes get
+// io.ErrClosedPipe, as do Writes from the other end. Reads on the other end get err,
+// or io.EOF if nil, once they have drained what was already written
+func (c *PipeConn) CloseWithError(err error) error {
+	if err == nil {
+		err = io.EOF
+	}
+	c.once.Do(func() {
+		close(c.done)
+		c.rd.mu.Lock()
+		c.rd.werr = io.ErrClosedPipe
+		c.rd.notify()
+		c.rd.mu.Unlock()
+		c.wr.mu.Lock()
+		c.wr.rerr = err
+		c.wr.notify()
+		c.wr.mu.Unlock()
+		c.readDeadline.stop()
+		c.writeDeadline.stop()
+	})
+	return nil
+}
+
+// LocalAddr implements net.Conn
+func (c *PipeConn) LocalAddr() net.Addr {
+	return pipeAddr{}
+}
+
+// RemoteAddr implements net.Conn
+func (c *PipeConn) RemoteAddr() net.Addr {
+	return pipeAddr{}
+}
+
+// SetDeadline sets both the read and write deadlines
+func (c *PipeConn) SetDeadline(t time.Time) error {
+	if isClosed(c.done) {
+		return io.ErrClosedPipe
+	}
+	c.readDeadline.set(t)
+	c.writeDeadline.set(t)
+	return nil
+}
+
+// SetReadDeadline makes pending and future Reads fail with os.ErrDeadlineExceeded
+// after t, the zero time means no deadline
+func (c *PipeConn) SetReadDeadline(t time.Time) error {
+	if isClosed(c.done) {
+		return io.ErrClosedPipe
+	}
+	c.readDeadline.set(t)
+	return nil
+}
+
+// SetWriteDeadline makes pending and future Writes fail with os.ErrDeadlineExceeded
+// after t, the zero time means no deadline
+func (c *PipeConn) SetWriteDeadline(t time.Time) error {
+	if isClosed(c.done) {
+		return io.ErrClosedPipe
+	}
+	c.writeDeadline.set(t)
+	return nil
+}
+
+// notify wakes every waiter, must hold mu
+func (h *pipeHalf) notify() {
+	close(h.changed)
+	h.changed = make(chan struct{})
+}
+
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string { return "pipe" }
+func (pipeAddr) String() string  { return "pipe" }
+
+// pipeDeadline is a channel that closes when the deadline passes, as in net.Pipe
+type pipeDeadline struct {
+	mu     sync.Mutex
+	clock  Clock
+	timer  Timer
+	cancel chan struct{}
+}
+
+func newPipeDeadline(clock Clock) *pipeDeadline {
+	return &pipeDeadline{clock: clock, cancel: make(chan struct{})}
+}
+
+// set replaces the deadline, the zero time clears it
+func (d *pipeDeadline) set(t time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil && !d.timer.Stop() {
+		<-d.cancel // the old timer fired or is firing, wait for it to close
+	}
+	d.timer = nil
+
+	closed := isClosed(d.cancel)
+	if t.IsZero() {
+		if closed {
+			d.cancel = make(chan struct{})
+		}
+		return
+	}
+	if dur := t.Sub(d.clock.Now()); dur > 0 {
+		if closed {
+			d.cancel = make(chan struct{})
+		}
+		cancel := d.cancel
+		d.timer = d.clock.AfterFunc(dur, func() {
+			close(cancel)
+		})
+		return
+	}
+	if !closed {
+		close(d.cancel)
+	}
+}
+
+// stop releases the timer
+func (d *pipeDeadline) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+}
+
+// wait returns a channel closed once the deadline passes
+func (d *pipeDeadline) wait() chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.cancel
+}
+
+func isClosed(c chan struct{}) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
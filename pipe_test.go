@@ -0,0 +1,152 @@
+package ringslice
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var _ net.Conn = (*PipeConn)(nil)
+
+func TestPipeDuplex(t *testing.T) {
+	a, b := Pipe(8)
+	n, err := a.Write([]byte("ping"))
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+	n, err = b.Write([]byte("pong"))
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+
+	buf := make([]byte, 8)
+	n, err = b.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "ping", string(buf[:n]))
+	n, err = a.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "pong", string(buf[:n]))
+}
+
+func TestPipeBlocking(t *testing.T) {
+	a, b := Pipe(4)
+
+	// a write bigger than the ring completes as the reader drains it
+	done := make(chan error)
+	go func() {
+		_, err := a.Write([]byte("0123456789"))
+		done <- err
+	}()
+	got, err := io.ReadAll(io.LimitReader(b, 10))
+	require.NoError(t, err)
+	require.Equal(t, "0123456789", string(got))
+	require.NoError(t, <-done)
+
+	// a read waits for a write
+	read := make(chan string)
+	go func() {
+		buf := make([]byte, 4)
+		n, _ := b.Read(buf)
+		read <- string(buf[:n])
+	}()
+	a.Write([]byte("late"))
+	require.Equal(t, "late", <-read)
+}
+
+func TestPipeDeadlines(t *testing.T) {
+	clock := NewManualClock(epoch)
+	a, b := Pipe(2, WithClock(clock))
+
+	// a pending read fails once the clock passes the deadline
+	require.NoError(t, b.SetReadDeadline(epoch.Add(time.Second)))
+	read := make(chan error)
+	go func() {
+		_, err := b.Read(make([]byte, 1))
+		read <- err
+	}()
+	clock.Advance(time.Second)
+	err := <-read
+	require.Equal(t, os.ErrDeadlineExceeded, err)
+	var netErr net.Error
+	require.True(t, errors.As(err, &netErr) && netErr.Timeout())
+
+	// clearing the deadline lets reads through again
+	require.NoError(t, b.SetReadDeadline(time.Time{}))
+	a.Write([]byte("x"))
+	n, err := b.Read(make([]byte, 1))
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	// a deadline in the past fails a write straight away, even with room
+	require.NoError(t, a.SetWriteDeadline(epoch))
+	_, err = a.Write([]byte("y"))
+	require.Equal(t, os.ErrDeadlineExceeded, err)
+
+	// a blocked write reports what it managed before the deadline
+	require.NoError(t, a.SetDeadline(clock.Now().Add(time.Second)))
+	wrote := make(chan int)
+	go func() {
+		n, err := a.Write([]byte("abc"))
+		if err != os.ErrDeadlineExceeded {
+			t.Errorf("blocked write got %v", err)
+		}
+		wrote <- n
+	}()
+	for {
+		b.rd.mu.Lock()
+		full := b.rd.ring.Len() == 2
+		b.rd.mu.Unlock()
+		if full {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(time.Second)
+	require.Equal(t, 2, <-wrote)
+}
+
+func TestPipeClose(t *testing.T) {
+	a, b := Pipe(8)
+	a.Write([]byte("last"))
+	require.NoError(t, a.Close())
+	require.NoError(t, a.Close())
+
+	// the peer drains then sees EOF, and can't write back
+	got, err := io.ReadAll(b)
+	require.NoError(t, err)
+	require.Equal(t, "last", string(got))
+	_, err = b.Write([]byte("x"))
+	require.Equal(t, io.ErrClosedPipe, err)
+
+	// our own end is closed both ways
+	_, err = a.Read(make([]byte, 1))
+	require.Equal(t, io.ErrClosedPipe, err)
+	_, err = a.Write([]byte("x"))
+	require.Equal(t, io.ErrClosedPipe, err)
+	require.Equal(t, io.ErrClosedPipe, a.SetDeadline(time.Time{}))
+}
+
+func TestPipeCloseWithError(t *testing.T) {
+	a, b := Pipe(8)
+	boom := errors.New("boom")
+
+	read := make(chan error)
+	go func() {
+		_, err := b.Read(make([]byte, 1))
+		read <- err
+	}()
+	require.NoError(t, a.CloseWithError(boom))
+	require.Equal(t, boom, <-read)
+
+	// closing unblocks our own pending read
+	_, b = Pipe(8)
+	go func() {
+		_, err := b.Read(make([]byte, 1))
+		read <- err
+	}()
+	b.Close()
+	require.Equal(t, io.ErrClosedPipe, <-read)
+}
@@ -0,0 +1,206 @@
+package ringslice
+
+import (
+	"math"
+	"sort"
+)
+
+// QuantileWindow is a ring that answers quantile queries over value of its contents
+// as entries are appended and deleted. By default it keeps the values sorted for exact
+// answers, which costs O(n) per update so suits small windows. WithSketch swaps in a
+// DDSketch, whose buckets can be decremented on delete unlike t-digest or KLL, for
+// O(1) updates with bounded relative error
+type QuantileWindow[T any] struct {
+	ring  *Slice[T]
+	value func(T) float64
+	est   quantiler
+}
+
+// quantiler is an order statistics store that supports deletes
+type quantiler interface {
+	add(v float64)
+	remove(v float64)
+	// quantile returns the value at rank floor(q*(n-1)), n > 0
+	quantile(q float64) float64
+}
+
+// NewQuantileWindow creates a window of capacity. WithFullPolicy(FullOverwrite) gives
+// the last capacity entries, Purge by time gives the last T
+func NewQuantileWindow[T any](capacity int, value func(T) float64, opts ...Option) *QuantileWindow[T] {
+	c := newConfig(opts)
+	var est quantiler = &sortedValues{}
+	if c.sketchAccuracy > 0 {
+		est = newDDSketch(c.sketchAccuracy)
+	}
+	return &QuantileWindow[T]{
+		ring:  NewSlice[T](capacity, false, nil, opts...),
+		value: value,
+		est:   est,
+	}
+}
+
+// Append adds an entry, any entry evicted to make room leaves the window
+func (w *QuantileWindow[T]) Append(value T) error {
+	evicted, ok, err := w.ring.AppendEvict(value)
+	if err != nil {
+		return err
+	}
+	if ok {
+		w.est.remove(w.value(evicted))
+	}
+	w.est.add(w.value(value))
+	return nil
+}
+
+// DeleteCount deletes count of the oldest entries, see Slice.DeleteCount
+func (w *QuantileWindow[T]) DeleteCount(count int) []T {
+	l := w.ring.DeleteCount(count)
+	for _, v := range l {
+		w.est.remove(w.value(v))
+	}
+	return l
+}
+
+// Purge deletes all entries with key <= want, see Slice.Purge
+func (w *QuantileWindow[T]) Purge(want int64, key func(T) int64) []T {
+	l := w.ring.Purge(want, key)
+	for _, v := range l {
+		w.est.remove(w.value(v))
+	}
+	return l
+}
+
+// Count is the number of entries in the window
+func (w *QuantileWindow[T]) Count() int {
+	return w.ring.Len()
+}
+
+// Quantile returns the value at quantile q in [0, 1], e.g. 0.99 for p99
+func (w *QuantileWindow[T]) Quantile(q float64) (float64, error) {
+	if q < 0 || q > 1 || math.IsNaN(q) {
+		return 0, ErrOutOfRange
+	}
+	if w.ring.Len() == 0 {
+		return 0, ErrEmpty
+	}
+	return w.est.quantile(q), nil
+}
+
+// sortedValues is the exact store
+type sortedValues struct {
+	values []float64
+}
+
+func (s *sortedValues) add(v float64) {
+	i := sort.SearchFloat64s(s.values, v)
+	s.values = append(s.values, 0)
+	copy(s.values[i+1:], s.values[i:])
+	s.values[i] = v
+}
+
+func (s *sortedValues) remove(v float64) {
+	i := sort.SearchFloat64s(s.values, v)
+	if i < len(s.values) && s.values[i] == v {
+		s.values = append(s.values[:i], s.values[i+1:]...)
+	}
+}
+
+func (s *sortedValues) quantile(q float64) float64 {
+	return s.values[int(q*float64(len(s.values)-1))]
+}
+
+// ddSketch buckets values logarithmically so any value it returns is within
+// relative accuracy alpha of the true one. Counts are kept per bucket index so
+// removing a value is just a decrement
+type ddSketch struct {
+	gamma    float64
+	logGamma float64
+	pos      map[int]int // buckets of positive values
+	neg      map[int]int // buckets of the magnitude of negative values
+	zero     int
+	count    int
+}
+
+// minIndexable keeps log away from denormals, smaller magnitudes count as zero
+const minIndexable = 1e-300
+
+func newDDSketch(alpha float64) *ddSketch {
+	gamma := (1 + alpha) / (1 - alpha)
+	return &ddSketch{
+		gamma:    gamma,
+		logGamma: math.Log(gamma),
+		pos:      map[int]int{},
+		neg:      map[int]int{},
+	}
+}
+
+func (d *ddSketch) index(v float64) int {
+	return int(math.Ceil(math.Log(v) / d.logGamma))
+}
+
+func (d *ddSketch) bucketValue(i int) float64 {
+	return 2 * math.Pow(d.gamma, float64(i)) / (d.gamma + 1)
+}
+
+func (d *ddSketch) add(v float64) {
+	d.adjust(v, 1)
+}
+
+func (d *ddSketch) remove(v float64) {
+	d.adjust(v, -1)
+}
+
+func (d *ddSketch) adjust(v float64, delta int) {
+	d.count += delta
+	switch {
+	case v > minIndexable:
+		bump(d.pos, d.index(v), delta)
+	case v < -minIndexable:
+		bump(d.neg, d.index(-v), delta)
+	default:
+		d.zero += delta
+	}
+}
+
+func bump(buckets map[int]int, i, delta int) {
+	buckets[i] += delta
+	if buckets[i] <= 0 {
+		delete(buckets, i)
+	}
+}
+
+func (d *ddSketch) quantile(q float64) float64 {
+	rank := int(q * float64(d.count-1))
+	seen := 0
+	// most negative first, so the largest magnitude negative bucket
+	for _, i := range sortedKeys(d.neg, true) {
+		seen += d.neg[i]
+		if seen > rank {
+			return -d.bucketValue(i)
+		}
+	}
+	seen += d.zero
+	if seen > rank {
+		return 0
+	}
+	for _, i := range sortedKeys(d.pos, false) {
+		seen += d.pos[i]
+		if seen > rank {
+			return d.bucketValue(i)
+		}
+	}
+	return 0 // only if removes didn't match adds
+}
+
+func sortedKeys(buckets map[int]int, descending bool) []int {
+	keys := make([]int, 0, len(buckets))
+	for k := range buckets {
+		keys = append(keys, k)
+	}
+	if descending {
+		sort.Sort(sort.Reverse(sort.IntSlice(keys)))
+	} else {
+		sort.Ints(keys)
+	}
+	return keys
+}
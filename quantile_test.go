@@ -0,0 +1,105 @@
+package ringslice
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuantileWindowExact(t *testing.T) {
+	tests := []struct {
+		name    string
+		appends []float64
+		delete  int
+		q       float64
+		want    float64
+	}{
+		{name: "single", appends: []float64{3}, q: 0.99, want: 3},
+		{name: "median odd", appends: []float64{5, 1, 3}, q: 0.5, want: 3},
+		{name: "min", appends: []float64{5, 1, 3}, q: 0, want: 1},
+		{name: "max", appends: []float64{5, 1, 3}, q: 1, want: 5},
+		{name: "after delete", appends: []float64{1, 5, 3, 4}, delete: 1, q: 0, want: 3},
+		{name: "negative", appends: []float64{-2, 0, 2}, q: 0, want: -2},
+		{name: "duplicates", appends: []float64{2, 2, 7, 2}, delete: 1, q: 0.5, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewQuantileWindow[float64](4, func(v float64) float64 { return v })
+			for _, v := range tt.appends {
+				require.NoError(t, w.Append(v))
+			}
+			w.DeleteCount(tt.delete)
+			got, err := w.Quantile(tt.q)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestQuantileWindowErrors(t *testing.T) {
+	w := NewQuantileWindow[float64](1, func(v float64) float64 { return v })
+	_, err := w.Quantile(0.5)
+	require.Equal(t, ErrEmpty, err)
+	w.Append(1)
+	_, err = w.Quantile(1.5)
+	require.Equal(t, ErrOutOfRange, err)
+	_, err = w.Quantile(math.NaN())
+	require.Equal(t, ErrOutOfRange, err)
+}
+
+func TestQuantileWindowPurge(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithSketch(0.01)}} {
+		w := NewQuantileWindow[sample](4, func(s sample) float64 { return float64(s.val) }, opts...)
+		for i, v := range []int{100, 100, 1, 2} {
+			w.Append(sample{epoch.Add(time.Duration(i) * time.Second), v})
+		}
+		w.Purge(epoch.Add(time.Second).UnixNano(), func(s sample) int64 { return s.at.UnixNano() })
+		require.Equal(t, 2, w.Count())
+		got, err := w.Quantile(1)
+		require.NoError(t, err)
+		require.InDelta(t, 2, got, 0.02)
+	}
+}
+
+// TestQuantileWindowSketch checks the sketch stays within its accuracy of the exact answer
+// over a sliding window of mixed sign values
+func TestQuantileWindowSketch(t *testing.T) {
+	const alpha = 0.02
+	rnd := rand.New(rand.NewSource(1))
+	value := func(v float64) float64 { return v }
+	exact := NewQuantileWindow[float64](500, value, WithFullPolicy(FullOverwrite))
+	sketch := NewQuantileWindow[float64](500, value, WithFullPolicy(FullOverwrite), WithSketch(alpha))
+	for i := 0; i < 5000; i++ {
+		v := rnd.ExpFloat64() * 100
+		if rnd.Intn(10) == 0 {
+			v = -v
+		}
+		exact.Append(v)
+		sketch.Append(v)
+		if i%250 != 0 {
+			continue
+		}
+		for _, q := range []float64{0, 0.5, 0.95, 0.99, 1} {
+			want, err := exact.Quantile(q)
+			require.NoError(t, err)
+			got, err := sketch.Quantile(q)
+			require.NoError(t, err)
+			require.InDelta(t, want, got, math.Abs(want)*alpha+1e-9, "q %v", q)
+		}
+	}
+}
+
+func TestSortedValues(t *testing.T) {
+	s := &sortedValues{}
+	for _, v := range []float64{3, 1, 2, 2} {
+		s.add(v)
+	}
+	s.remove(2)
+	s.remove(9) // not present
+	require.True(t, sort.Float64sAreSorted(s.values))
+	require.Equal(t, []float64{1, 2, 3}, s.values)
+}
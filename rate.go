@@ -0,0 +1,151 @@
+package ringslice
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// ewmaInterval is how often the moving averages take a sample, as in the unix load average
+const ewmaInterval = 5 * time.Second
+
+// Meter measures events per second. Rate is exact to within resolution over a sliding
+// window, kept as a Keyed ring of marks coalesced to resolution, and the 1, 5 and 15
+// minute rates are exponentially weighted moving averages updated lazily from the Clock
+type Meter struct {
+	mu         sync.Mutex
+	clock      Clock
+	window     time.Duration
+	resolution time.Duration
+	marks      *Keyed[meterMark]
+	inWindow   int64
+	count      int64
+	ewmas      [3]ewma
+	lastTick   time.Time
+	uncounted  int64
+}
+
+type meterMark struct {
+	at int64 // unix nanos truncated to resolution
+	n  int64
+}
+
+// MeterSnapshot is a consistent read of a Meter, rates are per second
+type MeterSnapshot struct {
+	Count  int64
+	Rate   float64
+	Rate1  float64
+	Rate5  float64
+	Rate15 float64
+}
+
+// NewMeter creates a meter with a rate over window, marks within the same resolution
+// share a slot so the ring never holds more than window/resolution+2. A resolution of 0
+// or over window is the whole window. WithClock replaces RealClock
+func NewMeter(window, resolution time.Duration, opts ...Option) (*Meter, error) {
+	if window <= 0 || resolution < 0 {
+		return nil, fmt.Errorf("ringslice: window must be positive and resolution not negative")
+	}
+	c := newConfig(opts)
+	clock := c.clock
+	if clock == nil {
+		clock = RealClock
+	}
+	if resolution == 0 || resolution > window {
+		resolution = window
+	}
+	m := &Meter{
+		clock:      clock,
+		window:     window,
+		resolution: resolution,
+		marks:      NewKeyed[meterMark](int(window/resolution)+2, nil, func(m meterMark) int64 { return m.at }),
+		lastTick:   clock.Now(),
+	}
+	for i, minutes := range []float64{1, 5, 15} {
+		m.ewmas[i].alpha = 1 - math.Exp(-ewmaInterval.Seconds()/60/minutes)
+	}
+	return m, nil
+}
+
+// Mark records n events now. If they can't be kept in the ring nothing is counted
+func (m *Meter) Mark(n int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.clock.Now()
+	m.update(now)
+
+	at := now.Truncate(m.resolution).UnixNano()
+	if back, err := m.marks.Back(); err == nil && back.at == at {
+		back.n += n
+		m.marks.Set(m.marks.Len()-1, back)
+	} else if err := m.marks.Append(meterMark{at: at, n: n}); err != nil {
+		return err
+	}
+	m.count += n
+	m.uncounted += n
+	m.inWindow += n
+	return nil
+}
+
+// Rate is events per second over the window
+func (m *Meter) Rate() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.update(m.clock.Now())
+	return m.rate()
+}
+
+// Snapshot reads every rate at the same instant
+func (m *Meter) Snapshot() MeterSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.update(m.clock.Now())
+	return MeterSnapshot{
+		Count:  m.count,
+		Rate:   m.rate(),
+		Rate1:  m.ewmas[0].perSecond(),
+		Rate5:  m.ewmas[1].perSecond(),
+		Rate15: m.ewmas[2].perSecond(),
+	}
+}
+
+func (m *Meter) rate() float64 {
+	return float64(m.inWindow) / m.window.Seconds()
+}
+
+// update purges marks that left the window and catches the averages up, must hold mu
+func (m *Meter) update(now time.Time) {
+	// a slot is in the window until its whole resolution has passed out of it
+	for _, gone := range m.marks.Purge(now.Add(-m.window).Add(-m.resolution).UnixNano()) {
+		m.inWindow -= gone.n
+	}
+	for now.Sub(m.lastTick) >= ewmaInterval {
+		for i := range m.ewmas {
+			m.ewmas[i].tick(m.uncounted)
+		}
+		m.uncounted = 0 // later missed intervals had no events
+		m.lastTick = m.lastTick.Add(ewmaInterval)
+	}
+}
+
+// ewma is a moving average of events per ewmaInterval
+type ewma struct {
+	alpha float64
+	rate  float64
+	init  bool
+}
+
+func (e *ewma) tick(n int64) {
+	instant := float64(n) / ewmaInterval.Seconds()
+	if !e.init {
+		e.rate = instant
+		e.init = true
+		return
+	}
+	e.rate += e.alpha * (instant - e.rate)
+}
+
+func (e *ewma) perSecond() float64 {
+	return e.rate
+}
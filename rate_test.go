@@ -0,0 +1,126 @@
+package ringslice
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeterRate(t *testing.T) {
+	type step struct {
+		advance time.Duration
+		mark    int64
+	}
+	tests := []struct {
+		name      string
+		steps     []step
+		wantRate  float64
+		wantCount int64
+	}{
+		{
+			name:      "nothing",
+			wantRate:  0,
+			wantCount: 0,
+		},
+		{
+			name:      "in window",
+			steps:     []step{{mark: 30}, {advance: 30 * time.Second, mark: 30}},
+			wantRate:  1,
+			wantCount: 60,
+		},
+		{
+			name:      "slid out",
+			steps:     []step{{mark: 600}, {advance: 30 * time.Second, mark: 60}, {advance: 31 * time.Second}},
+			wantRate:  1,
+			wantCount: 660,
+		},
+		{
+			name:      "all gone",
+			steps:     []step{{mark: 10}, {advance: time.Hour}},
+			wantRate:  0,
+			wantCount: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewManualClock(epoch)
+			m, err := NewMeter(time.Minute, time.Second, WithClock(clock))
+			require.NoError(t, err)
+			for _, st := range tt.steps {
+				clock.Advance(st.advance)
+				if st.mark > 0 {
+					require.NoError(t, m.Mark(st.mark))
+				}
+			}
+			require.Equal(t, tt.wantRate, m.Rate())
+			require.Equal(t, tt.wantCount, m.Snapshot().Count)
+		})
+	}
+}
+
+func TestMeterCoalesces(t *testing.T) {
+	clock := NewManualClock(epoch)
+	m, err := NewMeter(10*time.Second, time.Second, WithClock(clock))
+	require.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, m.Mark(1))
+		clock.Advance(100 * time.Millisecond)
+		var held int64
+		for _, mark := range m.marks.All() {
+			held += mark.n
+		}
+		require.Equal(t, m.inWindow, held) // nothing was dropped by a full ring
+	}
+	require.InDelta(t, 10, m.Rate(), 1)
+}
+
+func TestMeterEWMA(t *testing.T) {
+	clock := NewManualClock(epoch)
+	m, err := NewMeter(time.Minute, time.Second, WithClock(clock))
+	require.NoError(t, err)
+
+	// a steady 10 per second converges every average on 10
+	for i := 0; i < 60*30; i++ {
+		require.NoError(t, m.Mark(10))
+		clock.Advance(time.Second)
+	}
+	s := m.Snapshot()
+	require.InDelta(t, 10, s.Rate, 0.01)
+	require.InDelta(t, 10, s.Rate1, 0.01)
+	require.InDelta(t, 10, s.Rate5, 0.01)
+	require.InDelta(t, 10, s.Rate15, 0.2)
+
+	// a minute of silence decays each by exp(-1/minutes)
+	clock.Advance(time.Minute)
+	s = m.Snapshot()
+	require.Equal(t, 0.0, s.Rate)
+	require.InDelta(t, 10*math.Exp(-1), s.Rate1, 0.01)
+	require.InDelta(t, 10*math.Exp(-1.0/5), s.Rate5, 0.05)
+	require.InDelta(t, 10*math.Exp(-1.0/15), s.Rate15, 0.2)
+}
+
+func TestMeterArgs(t *testing.T) {
+	for _, args := range [][2]time.Duration{{0, time.Second}, {-time.Minute, time.Second}, {time.Minute, -time.Second}} {
+		_, err := NewMeter(args[0], args[1])
+		require.Error(t, err)
+	}
+	m, err := NewMeter(time.Minute, 0, WithClock(NewManualClock(epoch)))
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, m.resolution)
+}
+
+func TestMeterMarkFull(t *testing.T) {
+	clock := NewManualClock(epoch)
+	m, err := NewMeter(time.Minute, 30*time.Second, WithClock(clock))
+	require.NoError(t, err)
+	// marks from the future fill the ring, so there's no room for now
+	for i := 1; i <= m.marks.Cap(); i++ {
+		require.NoError(t, m.marks.Append(meterMark{at: epoch.Add(time.Duration(i) * time.Hour).UnixNano(), n: 1}))
+	}
+	require.Equal(t, ErrFull, m.Mark(5))
+	s := m.Snapshot()
+	require.Equal(t, int64(0), s.Count)
+	require.Equal(t, 0.0, s.Rate)
+}
@@ -3,36 +3,270 @@ package ringslice
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"sync"
 )
 
 // Slice struct
-type Slice struct {
-	values []interface{}
+type Slice[T any] struct {
+	values []T
 	used   int
 	start  int
 	cap    int
-	wipe   func(int, []interface{})
+	wipe   func(int, []T)
+	full   FullPolicy
+	space  *sync.Cond // only set for FullBlock
+	mods   int        // bumped on every append, delete and resize to catch iterator misuse
+	codec  Codec[T]   // nil means GobCodec
 }
 
-// NewSlice does
-func NewSlice(capacity int, debug bool, wipe func(int, []interface{})) *Slice {
-	return &Slice{values: make([]interface{}, capacity), cap: capacity, wipe: wipe}
+// NewSlice does. A nil wipe resets deleted indices to the zero value of T
+func NewSlice[T any](capacity int, debug bool, wipe func(int, []T), opts ...Option) *Slice[T] {
+	if wipe == nil {
+		wipe = wipeZero[T]
+	}
+	c := newConfig(opts)
+	s := &Slice[T]{values: make([]T, capacity), cap: capacity, wipe: wipe, full: c.full}
+	if c.codec != nil {
+		codec, ok := c.codec.(Codec[T])
+		if !ok {
+			panic("ringslice: WithCodec element type does not match NewSlice")
+		}
+		s.codec = codec
+	}
+	if c.full == FullBlock {
+		s.space = sync.NewCond(&sync.Mutex{})
+	}
+	return s
+}
+
+// InterfaceSlice is the untyped ring kept for callers of the original interface{} API
+type InterfaceSlice = Slice[interface{}]
+
+// NewInterfaceSlice creates an untyped ring, equivalent to NewSlice[interface{}]
+func NewInterfaceSlice(capacity int, debug bool, wipe func(int, []interface{}), opts ...Option) *InterfaceSlice {
+	return NewSlice[interface{}](capacity, debug, wipe, opts...)
+}
+
+// wipeZero is the default wipe, releasing whatever the index referenced
+func wipeZero[T any](i int, l []T) {
+	var zero T
+	l[i] = zero
+}
+
+// Append adds an entry if possible, what happens when full depends on the FullPolicy
+func (s *Slice[T]) Append(value T) error {
+	_, _, err := s.AppendEvict(value)
+	return err
 }
 
-// Append adds an entry if possible, returns error if full
-func (s *Slice) Append(value interface{}) error {
+// AppendEvict is Append but also returns the oldest entry if it was overwritten to make room.
+// The evicted index is passed to wipe before being reused
+func (s *Slice[T]) AppendEvict(value T) (evicted T, ok bool, err error) {
+	s.lock()
+	defer s.unlock()
+	return s.appendEvict(value)
+}
+
+func (s *Slice[T]) appendEvict(value T) (evicted T, ok bool, err error) {
 	if s.used == s.cap {
-		return errors.New("Index is full cannot append")
+		switch s.full {
+		case FullOverwrite:
+			if s.cap == 0 {
+				return evicted, false, ErrFull
+			}
+			evicted = s.values[s.start]
+			s.wipe(s.start, s.values)
+			s.values[s.start] = value // tail of a full ring is the old start
+			s.start = s.next(s.start)
+			s.mods++
+			return evicted, true, nil
+		case FullBlock:
+			if err = s.waitForRoom(1); err != nil {
+				return evicted, false, err
+			}
+		case FullGrow:
+			s.double()
+		default:
+			return evicted, false, ErrFull
+		}
 	}
 	ind := s.trueIndex(s.start, s.used) // next index is same as num written
 	s.values[ind] = value
 	s.used++
+	s.mods++
+	return evicted, false, nil
+}
+
+// Resize moves the contents oldest first into a new backing array of newCap,
+// returns ErrTooSmall if newCap is less than used
+func (s *Slice[T]) Resize(newCap int) error {
+	s.lock()
+	defer s.unlock()
+	if newCap < s.used {
+		return ErrTooSmall
+	}
+	s.resize(newCap)
+	return nil
+}
+
+// Grow adds n to the capacity, a negative n is Resize and so returns ErrTooSmall if
+// what is used would no longer fit
+func (s *Slice[T]) Grow(n int) error {
+	s.lock()
+	defer s.unlock()
+	if s.cap+n < s.used {
+		return ErrTooSmall
+	}
+	s.resize(s.cap + n)
+	return nil
+}
+
+// Shrink resizes to newCap, deleting and returning the oldest entries that no longer fit.
+// A negative newCap is 0
+func (s *Slice[T]) Shrink(newCap int) []T {
+	s.lock()
+	defer s.unlock()
+	if newCap < 0 {
+		newCap = 0
+	}
+	var l []T
+	if newCap < s.used {
+		l = s.deleteCount(s.used - newCap)
+	}
+	s.resize(newCap)
+	return l
+}
+
+// double is the FullGrow step
+func (s *Slice[T]) double() {
+	newCap := s.cap * 2
+	if newCap == 0 {
+		newCap = 1
+	}
+	s.resize(newCap)
+}
+
+// resize relinearizes so start becomes 0, caller guarantees newCap >= used
+func (s *Slice[T]) resize(newCap int) {
+	values := make([]T, newCap)
+	end := s.start + s.used
+	if end > s.cap {
+		end = s.cap
+	}
+	n := copy(values, s.values[s.start:end])
+	copy(values[n:], s.values[:s.used-n]) // wrapped part, if any
+	s.values = values
+	s.cap = newCap
+	s.start = 0
+	s.mods++
+	if s.space != nil {
+		s.space.Broadcast()
+	}
+}
+
+// Len is the number of entries held
+func (s *Slice[T]) Len() int {
+	s.lock()
+	defer s.unlock()
+	return s.used
+}
+
+// Cap is the number of entries that fit before Append hits the FullPolicy
+func (s *Slice[T]) Cap() int {
+	s.lock()
+	defer s.unlock()
+	return s.cap
+}
+
+// At returns the entry i away from the oldest, At(0) is the oldest
+func (s *Slice[T]) At(i int) (T, error) {
+	s.lock()
+	defer s.unlock()
+	if i < 0 || i >= s.used {
+		var zero T
+		return zero, ErrOutOfRange
+	}
+	return s.values[s.trueIndex(s.start, i)], nil
+}
+
+// Set replaces the entry i away from the oldest
+func (s *Slice[T]) Set(i int, value T) error {
+	s.lock()
+	defer s.unlock()
+	if i < 0 || i >= s.used {
+		return ErrOutOfRange
+	}
+	s.values[s.trueIndex(s.start, i)] = value
+	return nil
+}
+
+// Segments returns the contents oldest to newest as at most two slices of the backing array,
+// b is only non empty when the contents wrap. They alias the ring so are only valid until
+// the next append, delete or resize
+func (s *Slice[T]) Segments() (a, b []T) {
+	s.lock()
+	defer s.unlock()
+	end := s.start + s.used
+	if end <= s.cap {
+		return s.values[s.start:end], s.values[:0]
+	}
+	return s.values[s.start:], s.values[:end-s.cap]
+}
+
+// Front returns the oldest entry
+func (s *Slice[T]) Front() (T, error) {
+	s.lock()
+	defer s.unlock()
+	if s.used == 0 {
+		var zero T
+		return zero, ErrEmpty
+	}
+	return s.values[s.start], nil
+}
+
+// Back returns the newest entry
+func (s *Slice[T]) Back() (T, error) {
+	s.lock()
+	defer s.unlock()
+	if s.used == 0 {
+		var zero T
+		return zero, ErrEmpty
+	}
+	return s.values[s.trueIndex(s.start, s.used-1)], nil
+}
+
+// lock is only needed for FullBlock, where Append waits on deletes from another goroutine
+func (s *Slice[T]) lock() {
+	if s.space != nil {
+		s.space.L.Lock()
+	}
+}
+
+func (s *Slice[T]) unlock() {
+	if s.space != nil {
+		s.space.L.Unlock()
+	}
+}
+
+// waitForRoom blocks under FullBlock until n more entries fit, returning ErrFull if they
+// never can. The lock must be held
+func (s *Slice[T]) waitForRoom(n int) error {
+	if s.full != FullBlock {
+		return nil
+	}
+	if n > s.cap {
+		return ErrFull
+	}
+	for s.cap-s.used < n {
+		s.space.Wait()
+	}
 	return nil
 }
 
 // Values provides a set of values for debugging purposes by taking ring
 // and applying valuation function to each entry
-func (s *Slice) Values(value func(interface{}) int64) []int64 {
+func (s *Slice[T]) Values(value func(T) int64) []int64 {
 	v := []int64{}
 	for _, val := range s.values {
 		v = append(v, value(val))
@@ -41,23 +275,24 @@ func (s *Slice) Values(value func(interface{}) int64) []int64 {
 }
 
 // Stats prints information for debugging the slice
-func (s *Slice) Stats(value func(interface{}) int64) {
+func (s *Slice[T]) Stats(value func(T) int64) {
 	fmt.Println("used", s.used, "start", s.start, "valid", s.validate(value))
 }
 
-func (s *Slice) validate(value func(interface{}) int64) error {
+func (s *Slice[T]) validate(value func(T) int64) error {
 	if s.start > (s.cap - 1) {
 		return errors.New("illegal start")
 	}
 	if s.used <= 1 {
 		return nil
 	}
-	var last int64
-	for i := s.start; i < s.start+s.used-1; i++ {
-		next := value(s.values[s.trueIndex(i, 0)])
+	last := value(s.values[s.start])
+	for i := 1; i < s.used; i++ {
+		next := value(s.values[s.trueIndex(s.start, i)])
 		if next < last {
-			return errors.New("values out of order")
+			return ErrOutOfOrder
 		}
+		last = next
 	}
 	return nil
 }
@@ -65,18 +300,39 @@ func (s *Slice) validate(value func(interface{}) int64) error {
 // Purge wipes all indices that have a value determined by value function
 // to be <= want
 // TODO keep track of min and max whether we should even check
-func (s *Slice) Purge(want int64, value func(interface{}) int64) []interface{} {
-	ind := s.FindClosestBelowOrEqual(want, value)
-	if ind == -1 {
+func (s *Slice[T]) Purge(want int64, value func(T) int64) []T {
+	s.lock()
+	defer s.unlock()
+	n := s.search(func(v T) bool { return value(v) > want })
+	if n == 0 {
 		return nil
 	}
-	return s.DeleteBounds(s.start, ind)
+	return s.deleteCount(n)
+}
+
+// FindClosestAboveOrEqual finds the LOWEST value that is >= want, the counterpart of
+// FindClosestBelowOrEqual. If nothing is >= want, return -1. Like FindClosestBelowOrEqual
+// the result is an index into the backing array, Keyed.LowerBound gives the logical one
+func (s *Slice[T]) FindClosestAboveOrEqual(want int64, value func(T) int64) int {
+	i := s.search(func(v T) bool { return value(v) >= want })
+	if i == s.used {
+		return -1
+	}
+	return s.trueIndex(s.start, i)
+}
+
+// search returns the first logical index where f is true, or used if there is none.
+// f must be false then true over the contents, same as sort.Search
+func (s *Slice[T]) search(f func(T) bool) int {
+	return sort.Search(s.used, func(i int) bool {
+		return f(s.values[s.trueIndex(s.start, i)])
+	})
 }
 
 // FindClosestBelowOrEqual uses a binary search to find the HIGHEST value that is <= want
 // if nothing is <= value, return -1. Accounts for array wrapping around by determining the bounds
 // of the array if it were laid out contiguously
-func (s *Slice) FindClosestBelowOrEqual(want int64, value func(interface{}) int64) int {
+func (s *Slice[T]) FindClosestBelowOrEqual(want int64, value func(T) int64) int {
 	if s.used == 0 {
 		return -1
 	}
@@ -117,23 +373,21 @@ func (s *Slice) FindClosestBelowOrEqual(want int64, value func(interface{}) int6
 	}
 }
 
-// findLatestEquivalent walks clockwise in the array until the value changes, finding the highest
-// value <= want linearly. TODO: Could be optimized (val-count map) but intended case does not have any/ few equals.
-func (s *Slice) findLatestEquivalent(m int, want int64, value func(interface{}) int64) int {
-	for new := m; ; {
-		new = s.next(new)
-		if new == m {
-			return s.trueIndex(s.start+s.used-1, 0) // we've iterated back to where we started, all values equal
-		}
-		if value(s.values[s.trueIndex(new, 0)]) != want {
-			return s.prev(new)
+// findLatestEquivalent walks clockwise in the array until the value changes or the newest entry
+// is reached, finding the highest value <= want linearly. m may be past the end of the array.
+// TODO: Could be optimized (val-count map) but intended case does not have any/ few equals.
+func (s *Slice[T]) findLatestEquivalent(m int, want int64, value func(T) int64) int {
+	tail := s.trueIndex(s.start, s.used-1)
+	for cur := s.trueIndex(m, 0); ; cur = s.next(cur) {
+		if cur == tail || value(s.values[s.next(cur)]) != want {
+			return cur
 		}
 	}
 }
 
 // determineBoundary provides logic for case when pointers are 1 away from each other
 // happens when 1) all values are > 2) all values are < 3) there is a set below and a set above want
-func (s *Slice) determineBoundary(start, end int, want int64, value func(interface{}) int64) int {
+func (s *Slice[T]) determineBoundary(start, end int, want int64, value func(T) int64) int {
 	if value(s.values[s.trueIndex(end, 0)]) <= want {
 		return end
 	}
@@ -151,18 +405,37 @@ func countBetween(start, end, cap int) int {
 }
 
 // DeleteBounds deletes all indices [start,end] inclusive
-func (s *Slice) DeleteBounds(start, end int) []interface{} {
+func (s *Slice[T]) DeleteBounds(start, end int) []T {
 	count := countBetween(start, end, s.cap)
 	return s.DeleteCount(count)
 }
 
 // DeleteCount deletes count of values starting at start index
-func (s *Slice) DeleteCount(count int) []interface{} {
-	ind := s.start
+func (s *Slice[T]) DeleteCount(count int) []T {
+	s.lock()
+	defer s.unlock()
+	return s.deleteCount(count)
+}
+
+// DeleteCountInto is DeleteCount but appends the deleted values to dst instead of allocating
+func (s *Slice[T]) DeleteCountInto(dst []T, count int) []T {
+	s.lock()
+	defer s.unlock()
+	return s.deleteCountInto(dst, count)
+}
+
+func (s *Slice[T]) deleteCount(count int) []T {
 	if count > s.used {
 		count = s.used // save us some time
 	}
-	l := make([]interface{}, 0, count)
+	return s.deleteCountInto(make([]T, 0, count), count)
+}
+
+func (s *Slice[T]) deleteCountInto(l []T, count int) []T {
+	ind := s.start
+	if count > s.used {
+		count = s.used
+	}
 	for i := 0; i < count; i++ {
 		l = append(l, s.values[s.trueIndex(ind, 0)])
 		s.wipe(ind, s.values)
@@ -170,11 +443,14 @@ func (s *Slice) DeleteCount(count int) []interface{} {
 	}
 	s.used -= count
 	s.start = ind
+	if count > 0 {
+		s.freed()
+	}
 	return l
 }
 
 // safely iterate loop clockwise
-func (s *Slice) next(cur int) int {
+func (s *Slice[T]) next(cur int) int {
 	if cur == s.cap-1 {
 		return 0
 	}
@@ -182,7 +458,7 @@ func (s *Slice) next(cur int) int {
 }
 
 // safely iterate loop counterclockwise
-func (s *Slice) prev(cur int) int {
+func (s *Slice[T]) prev(cur int) int {
 	if cur == 0 {
 		return s.cap - 1
 	}
@@ -191,6 +467,6 @@ func (s *Slice) prev(cur int) int {
 
 // returns index of length AWAY from start taking into account wrap around
 // start,0 == start
-func (s *Slice) trueIndex(start, length int) int {
+func (s *Slice[T]) trueIndex(start, length int) int {
 	return (start + length) % s.cap
 }
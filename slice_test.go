@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -135,7 +136,7 @@ func TestDeleteCount(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			n := &Slice{
+			n := &InterfaceSlice{
 				values: tt.input,
 				start:  tt.start,
 				cap:    len(tt.input),
@@ -200,7 +201,7 @@ func TestDeleteBounds(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			n := &Slice{
+			n := &InterfaceSlice{
 				values: tt.input,
 				start:  tt.start,
 				cap:    len(tt.input),
@@ -258,7 +259,7 @@ func TestAppend(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			n := &Slice{
+			n := &InterfaceSlice{
 				values: tt.input,
 				start:  tt.start,
 				cap:    len(tt.input),
@@ -501,7 +502,7 @@ func TestSlice_FindClosestBelow(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			fmt.Println(tt.name)
-			s := &Slice{
+			s := &InterfaceSlice{
 				values: tt.fields.values,
 				used:   tt.fields.used,
 				start:  tt.fields.start,
@@ -571,3 +572,277 @@ func Test_countBetween(t *testing.T) {
 		})
 	}
 }
+
+func TestTypedSlice(t *testing.T) {
+	s := NewSlice[int64](3, false, nil)
+	for _, v := range []int64{1, 2, 3} {
+		require.NoError(t, s.Append(v))
+	}
+	require.Error(t, s.Append(4))
+
+	value := func(i int64) int64 { return i }
+	require.Equal(t, 1, s.FindClosestBelowOrEqual(2, value))
+	require.Equal(t, []int64{1, 2}, s.Purge(2, value))
+	require.Equal(t, []int64{0, 0, 3}, s.values)
+
+	require.NoError(t, s.Append(4))
+	require.Equal(t, []int64{3, 4}, s.DeleteCount(5))
+}
+
+func TestPurgeWrappedEqual(t *testing.T) {
+	value := func(i int64) int64 { return i }
+	s := NewSlice[int64](3, false, nil, WithFullPolicy(FullOverwrite))
+	for _, v := range []int64{1, 1, 4, 4, 4} {
+		s.Append(v)
+	}
+	require.Equal(t, 2, s.start)
+	require.Equal(t, 1, s.FindClosestBelowOrEqual(4, value)) // the newest, in backing terms
+	require.Nil(t, s.Purge(3, value))
+	require.Equal(t, []int64{4, 4, 4}, s.Purge(4, value))
+}
+
+func TestInterfaceSlice(t *testing.T) {
+	s := NewInterfaceSlice(2, false, wipeInt)
+	require.NoError(t, s.Append(1))
+	require.NoError(t, s.Append("two"))
+	require.Equal(t, []interface{}{1, "two"}, s.DeleteCount(2))
+	require.Equal(t, []interface{}{0, 0}, s.values)
+}
+
+func TestFullPolicyOverwrite(t *testing.T) {
+	wiped := []int{}
+	s := NewSlice[int](3, false, func(i int, l []int) {
+		wiped = append(wiped, l[i])
+		l[i] = 0
+	}, WithFullPolicy(FullOverwrite))
+	for i := 1; i <= 3; i++ {
+		_, ok, err := s.AppendEvict(i)
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+	for i := 4; i <= 5; i++ {
+		evicted, ok, err := s.AppendEvict(i)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, i-3, evicted)
+	}
+	require.Equal(t, []int{1, 2}, wiped)
+	require.Equal(t, []int{4, 5, 3}, s.values)
+	require.Equal(t, 2, s.start)
+	require.Equal(t, []int{3, 4, 5}, s.DeleteCount(3))
+}
+
+func TestFullPolicyBlock(t *testing.T) {
+	s := NewSlice[int](1, false, nil, WithFullPolicy(FullBlock))
+	require.NoError(t, s.Append(1))
+
+	done := make(chan error)
+	go func() {
+		done <- s.Append(2)
+	}()
+	select {
+	case <-done:
+		t.Fatal("append to full ring did not block")
+	case <-time.After(10 * time.Millisecond):
+	}
+	require.Equal(t, []int{1}, s.DeleteCount(1))
+	require.NoError(t, <-done)
+	require.Equal(t, []int{2}, s.DeleteCount(1))
+
+	// nothing can ever make room in an empty ring, so it doesn't wait
+	s = NewSlice[int](0, false, nil, WithFullPolicy(FullBlock))
+	require.Equal(t, ErrFull, s.Append(1))
+	require.Equal(t, ErrFull, s.PushFront(1))
+	n, err := s.AppendMany([]int{1})
+	require.Equal(t, ErrFull, err)
+	require.Equal(t, 0, n)
+}
+
+func TestFullPolicyReject(t *testing.T) {
+	s := NewSlice[int](1, false, nil)
+	require.NoError(t, s.Append(1))
+	_, ok, err := s.AppendEvict(2)
+	require.Equal(t, ErrFull, err)
+	require.False(t, ok)
+}
+
+func TestResize(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []interface{}
+		start   int
+		used    int
+		newCap  int
+		want    []interface{}
+		wantErr error
+	}{
+		{
+			name:   "grow no wrap",
+			input:  []interface{}{1, 2, 3, 0, 0},
+			start:  0,
+			used:   3,
+			newCap: 6,
+			want:   []interface{}{1, 2, 3, nil, nil, nil},
+		},
+		{
+			name:   "grow wrap",
+			input:  []interface{}{4, 5, 0, 2, 3},
+			start:  3,
+			used:   4,
+			newCap: 6,
+			want:   []interface{}{2, 3, 4, 5, nil, nil},
+		},
+		{
+			name:   "shrink to used",
+			input:  []interface{}{4, 0, 0, 2, 3},
+			start:  3,
+			used:   3,
+			newCap: 3,
+			want:   []interface{}{2, 3, 4},
+		},
+		{
+			name:    "too small",
+			input:   []interface{}{1, 2, 3},
+			start:   0,
+			used:    3,
+			newCap:  2,
+			want:    []interface{}{1, 2, 3},
+			wantErr: ErrTooSmall,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &InterfaceSlice{
+				values: tt.input,
+				start:  tt.start,
+				cap:    len(tt.input),
+				used:   tt.used,
+				wipe:   wipeInt,
+			}
+			require.Equal(t, tt.wantErr, n.Resize(tt.newCap))
+			require.Equal(t, tt.want, n.values)
+			require.Equal(t, len(tt.want), n.cap)
+		})
+	}
+}
+
+func TestShrinkAndGrow(t *testing.T) {
+	s := NewSlice[int](4, false, nil)
+	for i := 1; i <= 4; i++ {
+		require.NoError(t, s.Append(i))
+	}
+	s.DeleteCount(1)
+	require.NoError(t, s.Append(5))
+	require.Equal(t, []int{2, 3}, s.Shrink(2))
+	require.Equal(t, []int{4, 5}, s.values)
+	require.NoError(t, s.Grow(1))
+	require.NoError(t, s.Append(6))
+	require.Equal(t, ErrFull, s.Append(7))
+
+	// negative sizes can't lose anything held
+	require.Equal(t, ErrTooSmall, s.Grow(-1))
+	require.Equal(t, ErrTooSmall, s.Resize(-1))
+	require.Equal(t, 3, s.Cap())
+	require.Equal(t, []int{4, 5, 6}, s.DeleteCount(3))
+	require.NoError(t, s.Grow(-2))
+	require.Equal(t, 1, s.Cap())
+	require.Equal(t, ErrTooSmall, s.Grow(-2))
+
+	require.NoError(t, s.Append(7))
+	require.Equal(t, []int{7}, s.Shrink(-1))
+	require.Equal(t, 0, s.Cap())
+}
+
+func TestFullPolicyGrow(t *testing.T) {
+	s := NewSlice[int](0, false, nil, WithFullPolicy(FullGrow))
+	for i := 0; i < 5; i++ {
+		require.NoError(t, s.Append(i))
+	}
+	require.Equal(t, 8, s.cap)
+	require.Equal(t, []int{0, 1, 2, 3, 4}, s.DeleteCount(5))
+}
+
+func TestAccessors(t *testing.T) {
+	n := &InterfaceSlice{
+		values: []interface{}{4, 5, 0, 2, 3},
+		start:  3,
+		cap:    5,
+		used:   4,
+		wipe:   wipeInt,
+	}
+	require.Equal(t, 4, n.Len())
+	require.Equal(t, 5, n.Cap())
+
+	for i, want := range []int{2, 3, 4, 5} {
+		got, err := n.At(i)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+	_, err := n.At(4)
+	require.Equal(t, ErrOutOfRange, err)
+	_, err = n.At(-1)
+	require.Equal(t, ErrOutOfRange, err)
+
+	require.NoError(t, n.Set(2, 40))
+	require.Equal(t, []interface{}{40, 5, 0, 2, 3}, n.values)
+	require.Equal(t, ErrOutOfRange, n.Set(4, 1))
+
+	front, err := n.Front()
+	require.NoError(t, err)
+	require.Equal(t, 2, front)
+	back, err := n.Back()
+	require.NoError(t, err)
+	require.Equal(t, 5, back)
+
+	n.DeleteCount(4)
+	_, err = n.Front()
+	require.Equal(t, ErrEmpty, err)
+	_, err = n.Back()
+	require.Equal(t, ErrEmpty, err)
+}
+
+func TestSegments(t *testing.T) {
+	tests := []struct {
+		name  string
+		start int
+		used  int
+		wantA []interface{}
+		wantB []interface{}
+	}{
+		{name: "empty", start: 2, used: 0, wantA: []interface{}{}, wantB: []interface{}{}},
+		{name: "contiguous", start: 1, used: 3, wantA: []interface{}{2, 3, 4}, wantB: []interface{}{}},
+		{name: "to end", start: 2, used: 3, wantA: []interface{}{3, 4, 5}, wantB: []interface{}{}},
+		{name: "wrap", start: 3, used: 4, wantA: []interface{}{4, 5}, wantB: []interface{}{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &InterfaceSlice{
+				values: []interface{}{1, 2, 3, 4, 5},
+				start:  tt.start,
+				cap:    5,
+				used:   tt.used,
+				wipe:   wipeInt,
+			}
+			a, b := n.Segments()
+			require.Equal(t, tt.wantA, a)
+			require.Equal(t, tt.wantB, b)
+		})
+	}
+}
+
+func TestDeleteCountInto(t *testing.T) {
+	s := wrappedInts()
+	buf := make([]int, 0, 8)
+	buf = s.DeleteCountInto(buf, 2)
+	require.Equal(t, []int{3, 4}, buf)
+	buf = s.DeleteCountInto(buf, 10)
+	require.Equal(t, []int{3, 4, 5, 6, 7}, buf)
+	require.Equal(t, 0, s.Len())
+
+	s = wrappedInts()
+	buf = buf[:0]
+	require.Equal(t, 0.0, testing.AllocsPerRun(10, func() {
+		buf = s.DeleteCountInto(buf[:0], 1)
+		s.Append(0)
+	}))
+}
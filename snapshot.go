@@ -0,0 +1,191 @@
+package ringslice
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"sync"
+	"unsafe"
+)
+
+// Codec encodes single elements for MarshalBinary and gob, set with WithCodec
+type Codec[T any] interface {
+	Encode(value T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+// GobCodec encodes each element with encoding/gob, the default
+type GobCodec[T any] struct{}
+
+// Encode implements Codec
+func (GobCodec[T]) Encode(value T) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(&value)
+	return buf.Bytes(), err
+}
+
+// Decode implements Codec
+func (GobCodec[T]) Decode(data []byte) (T, error) {
+	var value T
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value)
+	return value, err
+}
+
+// JSONCodec encodes each element with encoding/json
+type JSONCodec[T any] struct{}
+
+// Encode implements Codec
+func (JSONCodec[T]) Encode(value T) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Decode implements Codec
+func (JSONCodec[T]) Decode(data []byte) (T, error) {
+	var value T
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
+
+const (
+	snapshotVersion = 1
+	// maxSnapshotBytes bounds the backing array a snapshot may ask for, so bad input
+	// can't make us allocate without limit
+	maxSnapshotBytes = 1 << 26
+)
+
+// MarshalBinary writes the capacity, FullPolicy and the values oldest first, each
+// through the Codec. The wipe, codec and any Keyed settings are not included
+func (s *Slice[T]) MarshalBinary() ([]byte, error) {
+	s.lock()
+	defer s.unlock()
+	codec := s.elementCodec()
+	buf := []byte{snapshotVersion}
+	buf = binary.AppendUvarint(buf, uint64(s.cap))
+	buf = binary.AppendUvarint(buf, uint64(s.full))
+	buf = binary.AppendUvarint(buf, uint64(s.used))
+	for i := 0; i < s.used; i++ {
+		b, err := codec.Encode(s.values[s.trueIndex(s.start, i)])
+		if err != nil {
+			return nil, err
+		}
+		buf = binary.AppendUvarint(buf, uint64(len(b)))
+		buf = append(buf, b...)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary replaces the contents, capacity and FullPolicy with those from
+// MarshalBinary, returning ErrCorrupt if data is malformed or the backing array would
+// be over 64MiB. The zero Slice may be used, the wipe and codec are kept if it has them
+func (s *Slice[T]) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != snapshotVersion {
+		return ErrCorrupt
+	}
+	codec := s.elementCodec()
+	data = data[1:]
+	var header [3]uint64 // cap, full, used
+	for i := range header {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return ErrCorrupt
+		}
+		header[i], data = v, data[n:]
+	}
+	capacity, used := header[0], header[2]
+	if !snapshotCapOK[T](capacity) || used > capacity || used > uint64(len(data)) || header[1] > uint64(FullGrow) {
+		return ErrCorrupt // every value takes at least its length byte
+	}
+	values := make([]T, capacity)
+	for i := range values[:used] {
+		size, n := binary.Uvarint(data)
+		if n <= 0 || size > uint64(len(data)-n) {
+			return ErrCorrupt
+		}
+		data = data[n:]
+		v, err := codec.Decode(data[:size])
+		if err != nil {
+			return err
+		}
+		values[i], data = v, data[size:]
+	}
+	if len(data) != 0 {
+		return ErrCorrupt
+	}
+	s.restore(values, int(used), FullPolicy(header[1]))
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder with MarshalBinary
+func (s *Slice[T]) GobEncode() ([]byte, error) {
+	return s.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder with UnmarshalBinary
+func (s *Slice[T]) GobDecode(data []byte) error {
+	return s.UnmarshalBinary(data)
+}
+
+// sliceJSON is the JSON form, values are encoded by encoding/json rather than the Codec
+type sliceJSON[T any] struct {
+	Cap    int        `json:"cap"`
+	Full   FullPolicy `json:"full"`
+	Values []T        `json:"values"`
+}
+
+// MarshalJSON writes {"cap":..., "full":..., "values":[...]} with the values oldest first
+func (s *Slice[T]) MarshalJSON() ([]byte, error) {
+	s.lock()
+	defer s.unlock()
+	out := sliceJSON[T]{Cap: s.cap, Full: s.full, Values: make([]T, 0, s.used)}
+	for i := 0; i < s.used; i++ {
+		out.Values = append(out.Values, s.values[s.trueIndex(s.start, i)])
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON is the counterpart of MarshalJSON, see UnmarshalBinary
+func (s *Slice[T]) UnmarshalJSON(data []byte) error {
+	var in sliceJSON[T]
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if in.Cap < 0 || !snapshotCapOK[T](uint64(in.Cap)) || len(in.Values) > in.Cap || in.Full < FullReject || in.Full > FullGrow {
+		return ErrCorrupt
+	}
+	values := make([]T, in.Cap)
+	copy(values, in.Values)
+	s.restore(values, len(in.Values), in.Full)
+	return nil
+}
+
+// snapshotCapOK reports whether a backing array of capacity T fits in maxSnapshotBytes
+func snapshotCapOK[T any](capacity uint64) bool {
+	var zero T
+	size := uint64(unsafe.Sizeof(zero))
+	if size == 0 {
+		size = 1
+	}
+	return capacity <= maxSnapshotBytes/size
+}
+
+func (s *Slice[T]) elementCodec() Codec[T] {
+	if s.codec == nil {
+		return GobCodec[T]{}
+	}
+	return s.codec
+}
+
+// restore swaps in values laid out from index 0, waking anyone blocked for space
+func (s *Slice[T]) restore(values []T, used int, full FullPolicy) {
+	if s.space == nil && full == FullBlock {
+		s.space = sync.NewCond(&sync.Mutex{})
+	}
+	s.lock()
+	defer s.unlock()
+	if s.wipe == nil {
+		s.wipe = wipeZero[T]
+	}
+	s.values, s.cap, s.used, s.start, s.full = values, len(values), used, 0, full
+	s.freed()
+}
@@ -0,0 +1,207 @@
+package ringslice
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*Slice[int])(nil)
+	_ encoding.BinaryUnmarshaler = (*Slice[int])(nil)
+	_ json.Marshaler             = (*Slice[int])(nil)
+	_ json.Unmarshaler           = (*Slice[int])(nil)
+	_ gob.GobEncoder             = (*Slice[int])(nil)
+	_ gob.GobDecoder             = (*Slice[int])(nil)
+)
+
+// wrappedSnapshot holds 3, 4, 5, 6 wrapped around a ring of 5
+func wrappedSnapshot(opts ...Option) *Slice[int] {
+	s := NewSlice[int](5, false, nil, append([]Option{WithFullPolicy(FullOverwrite)}, opts...)...)
+	for i := 1; i <= 6; i++ {
+		s.Append(i)
+	}
+	s.DeleteCount(1)
+	return s
+}
+
+func sliceContents[T any](s *Slice[T]) []T {
+	got := []T{}
+	for _, v := range s.All() {
+		got = append(got, v)
+	}
+	return got
+}
+
+// decimalCodec writes ints as text
+type decimalCodec struct{}
+
+func (decimalCodec) Encode(v int) ([]byte, error) {
+	return []byte(strconv.Itoa(v)), nil
+}
+
+func (decimalCodec) Decode(b []byte) (int, error) {
+	return strconv.Atoi(string(b))
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		encode  func(s *Slice[int]) ([]byte, error)
+		decode  func(s *Slice[int], b []byte) error
+		codec   []Option
+		wantRaw string
+	}{
+		{
+			name:   "binary",
+			encode: (*Slice[int]).MarshalBinary,
+			decode: (*Slice[int]).UnmarshalBinary,
+		},
+		{
+			name:   "binary with codec",
+			encode: (*Slice[int]).MarshalBinary,
+			decode: (*Slice[int]).UnmarshalBinary,
+			codec:  []Option{WithCodec[int](decimalCodec{})},
+			// version, cap, policy, count then length prefixed values
+			wantRaw: "\x01\x05\x01\x04\x013\x014\x015\x016",
+		},
+		{
+			name:    "json",
+			encode:  (*Slice[int]).MarshalJSON,
+			decode:  (*Slice[int]).UnmarshalJSON,
+			wantRaw: `{"cap":5,"full":1,"values":[3,4,5,6]}`,
+		},
+		{
+			name: "gob",
+			encode: func(s *Slice[int]) ([]byte, error) {
+				var buf bytes.Buffer
+				err := gob.NewEncoder(&buf).Encode(s)
+				return buf.Bytes(), err
+			},
+			decode: func(s *Slice[int], b []byte) error {
+				return gob.NewDecoder(bytes.NewReader(b)).Decode(s)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := tt.encode(wrappedSnapshot(tt.codec...))
+			require.NoError(t, err)
+			if tt.wantRaw != "" {
+				require.Equal(t, tt.wantRaw, string(b))
+			}
+
+			got := NewSlice[int](1, false, nil, tt.codec...)
+			require.NoError(t, tt.decode(got, b))
+			require.Equal(t, []int{3, 4, 5, 6}, sliceContents(got))
+			require.Equal(t, 5, got.Cap())
+
+			// the policy came across too
+			got.Append(7)
+			got.Append(8)
+			require.Equal(t, []int{4, 5, 6, 7, 8}, sliceContents(got))
+		})
+	}
+}
+
+func TestSnapshotZeroSlice(t *testing.T) {
+	b, err := wrappedSnapshot().MarshalBinary()
+	require.NoError(t, err)
+	var s Slice[int]
+	require.NoError(t, s.UnmarshalBinary(b))
+	require.Equal(t, []int{3, 4, 5, 6}, sliceContents(&s))
+	require.Equal(t, []int{3}, s.DeleteCount(1))
+
+	b, err = json.Marshal(map[string]*Slice[string]{"ring": NewSlice[string](2, false, nil)})
+	require.NoError(t, err)
+	require.Equal(t, `{"ring":{"cap":2,"full":0,"values":[]}}`, string(b))
+	var m map[string]*Slice[string]
+	require.NoError(t, json.Unmarshal(b, &m))
+	require.Equal(t, 2, m["ring"].Cap())
+	require.NoError(t, m["ring"].Append("a"))
+}
+
+func TestSnapshotFullBlock(t *testing.T) {
+	s := NewSlice[int](2, false, nil, WithFullPolicy(FullBlock))
+	s.Append(1)
+	s.Append(2)
+	b, err := s.MarshalBinary()
+	require.NoError(t, err)
+
+	var got Slice[int]
+	require.NoError(t, got.UnmarshalBinary(b))
+	done := make(chan struct{})
+	go func() {
+		got.Append(3) // blocks until there's room
+		close(done)
+	}()
+	got.PopFront()
+	<-done
+	require.Equal(t, []int{2, 3}, sliceContents(&got))
+}
+
+func TestSnapshotCorrupt(t *testing.T) {
+	good, err := wrappedSnapshot().MarshalBinary()
+	require.NoError(t, err)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty"},
+		{name: "version", data: append([]byte{9}, good[1:]...)},
+		{name: "truncated", data: good[:len(good)-1]},
+		{name: "trailing", data: append(append([]byte{}, good...), 0)},
+		{name: "more values than cap", data: []byte{1, 1, 0, 2, 1, 0, 1, 0}},
+		{name: "unknown policy", data: []byte{1, 1, 9, 0}},
+		{name: "short header", data: []byte{1, 1}},
+		{name: "cap past int", data: binary.AppendUvarint([]byte{1}, 1<<63)},
+		{name: "cap too big", data: append(binary.AppendUvarint([]byte{1}, 1<<62), 0, 0)},
+		{name: "cap just over the limit", data: append(binary.AppendUvarint([]byte{1}, maxSnapshotBytes/(strconv.IntSize/8)+1), 0, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := wrappedSnapshot()
+			require.Equal(t, ErrCorrupt, s.UnmarshalBinary(tt.data))
+			// left as it was
+			require.Equal(t, []int{3, 4, 5, 6}, sliceContents(s))
+		})
+	}
+
+	s := wrappedSnapshot()
+	require.Equal(t, ErrCorrupt, s.UnmarshalJSON([]byte(`{"cap":1,"values":[1,2]}`)))
+	require.Error(t, s.UnmarshalJSON([]byte(`{"cap":"x"}`)))
+	require.Equal(t, ErrCorrupt, s.UnmarshalJSON([]byte(`{"cap":4611686018427387904}`)))
+	require.Equal(t, ErrCorrupt, s.UnmarshalJSON([]byte(`{"cap":-1}`)))
+	require.Equal(t, []int{3, 4, 5, 6}, sliceContents(s))
+
+	// the limit is in bytes, a few bytes of input can't ask for gigabytes of big elements
+	var pages Slice[[4096]byte]
+	require.Equal(t, ErrCorrupt, pages.UnmarshalBinary([]byte{1, 0x80, 0x80, 0x80, 0x08, 0, 0}))
+	require.Equal(t, ErrCorrupt, pages.UnmarshalJSON([]byte(`{"cap":16385}`)))
+	require.NoError(t, pages.UnmarshalJSON([]byte(`{"cap":16384}`)))
+	require.Equal(t, 16384, pages.Cap())
+
+	boom := errors.New("boom")
+	s = NewSlice[int](2, false, nil, WithCodec[int](failingCodec{boom}))
+	s.Append(1)
+	_, err = s.MarshalBinary()
+	require.Equal(t, boom, err)
+}
+
+func TestSnapshotCodecMismatch(t *testing.T) {
+	require.Panics(t, func() {
+		NewSlice[int](2, false, nil, WithCodec[string](JSONCodec[string]{}))
+	})
+}
+
+type failingCodec struct{ err error }
+
+func (c failingCodec) Encode(int) ([]byte, error) { return nil, c.err }
+func (c failingCodec) Decode([]byte) (int, error) { return 0, c.err }
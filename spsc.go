@@ -0,0 +1,106 @@
+package ringslice
+
+import "sync/atomic"
+
+// cacheLine pads the producer and consumer counters apart so they don't false share
+const cacheLine = 64
+
+// SPSC is a lock free ring for exactly one producer goroutine and one consumer goroutine.
+// It uses the same layout as Slice, but start and start+used are kept as free running
+// atomic counters and capacity is a power of two so trueIndex is a mask instead of %
+type SPSC[T any] struct {
+	values []T
+	mask   uint64
+	_      [cacheLine]byte
+	head   uint64 // start, only written by the consumer
+	_      [cacheLine - 8]byte
+	tail   uint64 // start + used, only written by the producer
+	_      [cacheLine - 8]byte
+}
+
+// NewSPSC creates a ring holding at least capacity entries, rounded up to a power of two
+func NewSPSC[T any](capacity int) *SPSC[T] {
+	c := nextPowerOfTwo(capacity)
+	return &SPSC[T]{values: make([]T, c), mask: uint64(c - 1)}
+}
+
+func nextPowerOfTwo(n int) int {
+	c := 1
+	for c < n {
+		c <<= 1
+	}
+	return c
+}
+
+// TryAppend adds value if there is room, only call from the producer
+func (r *SPSC[T]) TryAppend(value T) bool {
+	tail := r.tail // we are the only writer
+	if tail-atomic.LoadUint64(&r.head) == uint64(len(r.values)) {
+		return false
+	}
+	r.values[r.trueIndex(tail)] = value
+	atomic.StoreUint64(&r.tail, tail+1)
+	return true
+}
+
+// TryAppendBatch adds as many of values as fit and returns how many, only call from the producer
+func (r *SPSC[T]) TryAppendBatch(values []T) int {
+	tail := r.tail
+	free := uint64(len(r.values)) - (tail - atomic.LoadUint64(&r.head))
+	n := uint64(len(values))
+	if n > free {
+		n = free
+	}
+	for i := uint64(0); i < n; i++ {
+		r.values[r.trueIndex(tail+i)] = values[i]
+	}
+	atomic.StoreUint64(&r.tail, tail+n)
+	return int(n)
+}
+
+// TryPop removes the oldest entry if there is one, only call from the consumer
+func (r *SPSC[T]) TryPop() (T, bool) {
+	var zero T
+	head := r.head // we are the only writer
+	if head == atomic.LoadUint64(&r.tail) {
+		return zero, false
+	}
+	ind := r.trueIndex(head)
+	v := r.values[ind]
+	r.values[ind] = zero // release for gc before handing the slot back
+	atomic.StoreUint64(&r.head, head+1)
+	return v, true
+}
+
+// TryPopBatch fills dst with up to len(dst) of the oldest entries and returns how many,
+// only call from the consumer
+func (r *SPSC[T]) TryPopBatch(dst []T) int {
+	var zero T
+	head := r.head
+	n := atomic.LoadUint64(&r.tail) - head
+	if n > uint64(len(dst)) {
+		n = uint64(len(dst))
+	}
+	for i := uint64(0); i < n; i++ {
+		ind := r.trueIndex(head + i)
+		dst[i] = r.values[ind]
+		r.values[ind] = zero
+	}
+	atomic.StoreUint64(&r.head, head+n)
+	return int(n)
+}
+
+// Len is a snapshot of the number of entries, may be stale by the time it returns
+func (r *SPSC[T]) Len() int {
+	head := atomic.LoadUint64(&r.head)
+	return int(atomic.LoadUint64(&r.tail) - head)
+}
+
+// Cap is the rounded up capacity
+func (r *SPSC[T]) Cap() int {
+	return len(r.values)
+}
+
+func (r *SPSC[T]) trueIndex(n uint64) uint64 {
+	return n & r.mask
+}
@@ -0,0 +1,168 @@
+package ringslice
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextPowerOfTwo(t *testing.T) {
+	tests := []struct {
+		in   int
+		want int
+	}{
+		{in: 0, want: 1},
+		{in: 1, want: 1},
+		{in: 3, want: 4},
+		{in: 8, want: 8},
+		{in: 9, want: 16},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, nextPowerOfTwo(tt.in))
+	}
+}
+
+func TestSPSCWrap(t *testing.T) {
+	r := NewSPSC[int](3)
+	require.Equal(t, 4, r.Cap())
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 4; i++ {
+			require.True(t, r.TryAppend(round*10+i))
+		}
+		require.False(t, r.TryAppend(-1))
+		require.Equal(t, 4, r.Len())
+		for i := 0; i < 3; i++ {
+			v, ok := r.TryPop()
+			require.True(t, ok)
+			require.Equal(t, round*10+i, v)
+		}
+		v, ok := r.TryPop()
+		require.True(t, ok)
+		require.Equal(t, round*10+3, v)
+		_, ok = r.TryPop()
+		require.False(t, ok)
+	}
+}
+
+func TestSPSCBatch(t *testing.T) {
+	r := NewSPSC[int](4)
+	require.Equal(t, 3, r.TryAppendBatch([]int{1, 2, 3}))
+	dst := make([]int, 2)
+	require.Equal(t, 2, r.TryPopBatch(dst))
+	require.Equal(t, []int{1, 2}, dst)
+	require.Equal(t, 3, r.TryAppendBatch([]int{4, 5, 6, 7, 8}))
+	dst = make([]int, 10)
+	require.Equal(t, 4, r.TryPopBatch(dst))
+	require.Equal(t, []int{3, 4, 5, 6}, dst[:4])
+	require.Equal(t, 0, r.Len())
+}
+
+func TestSPSCConcurrent(t *testing.T) {
+	const total = 100000
+	r := NewSPSC[int](64)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < total; {
+			if r.TryAppend(i) {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+	}()
+	for want := 0; want < total; {
+		v, ok := r.TryPop()
+		if !ok {
+			runtime.Gosched()
+			continue
+		}
+		require.Equal(t, want, v)
+		want++
+	}
+	wg.Wait()
+}
+
+func BenchmarkSPSC(b *testing.B) {
+	r := NewSPSC[int](1024)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < b.N; {
+			if _, ok := r.TryPop(); ok {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+	}()
+	for i := 0; i < b.N; {
+		if r.TryAppend(i) {
+			i++
+		} else {
+			runtime.Gosched()
+		}
+	}
+	<-done
+}
+
+func BenchmarkSPSCBatch(b *testing.B) {
+	r := NewSPSC[int](1024)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		dst := make([]int, 64)
+		for i := 0; i < b.N; {
+			n := r.TryPopBatch(dst)
+			if n == 0 {
+				runtime.Gosched()
+			}
+			i += n
+		}
+	}()
+	src := make([]int, 64)
+	for i := 0; i < b.N; {
+		want := b.N - i
+		if want > len(src) {
+			want = len(src)
+		}
+		n := r.TryAppendBatch(src[:want])
+		if n == 0 {
+			runtime.Gosched()
+		}
+		i += n
+	}
+	<-done
+}
+
+func BenchmarkMutexSlice(b *testing.B) {
+	s := NewSlice[int](1024, false, nil)
+	var mu sync.Mutex
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < b.N; {
+			mu.Lock()
+			n := len(s.DeleteCount(1))
+			mu.Unlock()
+			if n == 0 {
+				runtime.Gosched()
+			}
+			i += n
+		}
+	}()
+	for i := 0; i < b.N; {
+		mu.Lock()
+		err := s.Append(i)
+		mu.Unlock()
+		if err != nil {
+			runtime.Gosched()
+			continue
+		}
+		i++
+	}
+	<-done
+}
@@ -0,0 +1,124 @@
+package ringslice
+
+import (
+	"sync"
+	"time"
+)
+
+// TTLRing is a goroutine safe Keyed ring that purges entries older than ttl.
+// Expiry runs on every Append and read, or on an interval after StartExpiry,
+// and each purged batch is handed to the expired callback oldest first
+type TTLRing[T any] struct {
+	mu      sync.Mutex
+	ring    *Keyed[T]
+	ttl     time.Duration
+	clock   Clock
+	expired func([]T)
+	stop    chan struct{}
+}
+
+// NewTTLRing creates a ring of capacity whose entries are timed by at. expired may be nil.
+// opts are passed to the underlying Keyed ring, WithClock replaces RealClock. FullBlock
+// panics, Append would wait holding the lock that expiry needs to make room
+func NewTTLRing[T any](capacity int, ttl time.Duration, at func(T) time.Time, expired func([]T), opts ...Option) *TTLRing[T] {
+	c := newConfig(opts)
+	if c.full == FullBlock {
+		panic("ringslice: TTLRing does not support FullBlock")
+	}
+	clock := c.clock
+	if clock == nil {
+		clock = RealClock
+	}
+	return &TTLRing[T]{
+		ring:    NewKeyed[T](capacity, nil, func(v T) int64 { return at(v).UnixNano() }, opts...),
+		ttl:     ttl,
+		clock:   clock,
+		expired: expired,
+	}
+}
+
+// Append expires old entries then adds value
+func (r *TTLRing[T]) Append(value T) error {
+	r.mu.Lock()
+	gone := r.expire()
+	err := r.ring.Append(value)
+	r.mu.Unlock()
+	r.notify(gone)
+	return err
+}
+
+// Expire purges everything at or past the ttl and returns it
+func (r *TTLRing[T]) Expire() []T {
+	r.mu.Lock()
+	gone := r.expire()
+	r.mu.Unlock()
+	r.notify(gone)
+	return gone
+}
+
+// Len is the number of live entries
+func (r *TTLRing[T]) Len() int {
+	r.mu.Lock()
+	gone := r.expire()
+	n := r.ring.Len()
+	r.mu.Unlock()
+	r.notify(gone)
+	return n
+}
+
+// Snapshot copies the live entries oldest to newest
+func (r *TTLRing[T]) Snapshot() []T {
+	r.mu.Lock()
+	gone := r.expire()
+	a, b := r.ring.Segments()
+	l := make([]T, 0, len(a)+len(b))
+	l = append(append(l, a...), b...)
+	r.mu.Unlock()
+	r.notify(gone)
+	return l
+}
+
+// StartExpiry expires entries every interval on a background goroutine until Stop
+func (r *TTLRing[T]) StartExpiry(interval time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	r.stop = stop
+	ticker := r.clock.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C():
+				r.Expire()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the StartExpiry goroutine, if any
+func (r *TTLRing[T]) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stop != nil {
+		close(r.stop)
+		r.stop = nil
+	}
+}
+
+// expire must hold mu
+func (r *TTLRing[T]) expire() []T {
+	return r.ring.Purge(r.clock.Now().Add(-r.ttl).UnixNano())
+}
+
+// notify runs the callback outside mu so it may use the ring
+func (r *TTLRing[T]) notify(gone []T) {
+	if len(gone) > 0 && r.expired != nil {
+		r.expired(gone)
+	}
+}
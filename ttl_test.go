@@ -0,0 +1,119 @@
+package ringslice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type sample struct {
+	at  time.Time
+	val int
+}
+
+func sampleAt(s sample) time.Time { return s.at }
+
+var epoch = time.Unix(1561882872, 0)
+
+func TestTTLRing(t *testing.T) {
+	type step struct {
+		advance time.Duration
+		append  []int // seconds after epoch, also used as val
+		wantErr error
+	}
+	tests := []struct {
+		name        string
+		steps       []step
+		want        []int
+		wantExpired [][]int
+	}{
+		{
+			name:  "nothing expired",
+			steps: []step{{append: []int{0, 1, 2}}, {advance: 9 * time.Second}},
+			want:  []int{0, 1, 2},
+		},
+		{
+			name:        "expires at ttl",
+			steps:       []step{{append: []int{0, 1, 2}}, {advance: 11 * time.Second}},
+			want:        []int{2},
+			wantExpired: [][]int{{0, 1}},
+		},
+		{
+			name: "append makes room",
+			steps: []step{
+				{append: []int{0, 1, 2, 3}},
+				{append: []int{4}, wantErr: ErrFull},
+				{advance: 10 * time.Second, append: []int{10}},
+			},
+			want:        []int{1, 2, 3, 10},
+			wantExpired: [][]int{{0}},
+		},
+		{
+			name:        "all expired",
+			steps:       []step{{append: []int{0, 1}}, {advance: time.Minute}},
+			want:        []int{},
+			wantExpired: [][]int{{0, 1}},
+		},
+		{
+			name: "equal times wrapped",
+			steps: []step{
+				{append: []int{0, 1, 2}},
+				{advance: 12 * time.Second, append: []int{10, 10, 10, 10}}, // starts at backing index 3
+				{advance: 8 * time.Second},
+			},
+			want:        []int{},
+			wantExpired: [][]int{{0, 1, 2}, {10, 10, 10, 10}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewManualClock(epoch)
+			var expired [][]int
+			r := NewTTLRing[sample](4, 10*time.Second, sampleAt, func(l []sample) {
+				vals := []int{}
+				for _, s := range l {
+					vals = append(vals, s.val)
+				}
+				expired = append(expired, vals)
+			}, WithClock(clock))
+
+			for _, st := range tt.steps {
+				clock.Advance(st.advance)
+				for i, v := range st.append {
+					err := r.Append(sample{epoch.Add(time.Duration(v) * time.Second), v})
+					if i == len(st.append)-1 {
+						require.Equal(t, st.wantErr, err)
+					}
+				}
+			}
+			got := []int{}
+			for _, s := range r.Snapshot() {
+				got = append(got, s.val)
+			}
+			require.Equal(t, tt.want, got)
+			require.Equal(t, len(tt.want), r.Len())
+			require.Equal(t, tt.wantExpired, expired)
+		})
+	}
+}
+
+func TestTTLRingBackground(t *testing.T) {
+	clock := NewManualClock(epoch)
+	gone := make(chan []sample, 1)
+	r := NewTTLRing[sample](4, time.Second, sampleAt, func(l []sample) {
+		gone <- l
+	}, WithClock(clock))
+	require.NoError(t, r.Append(sample{epoch, 1}))
+	r.StartExpiry(time.Second)
+	defer r.Stop()
+
+	clock.Advance(time.Second)
+	require.Equal(t, []sample{{epoch, 1}}, <-gone)
+}
+
+func TestTTLRingFullBlock(t *testing.T) {
+	require.Panics(t, func() {
+		NewTTLRing[sample](4, time.Second, sampleAt, nil, WithFullPolicy(FullBlock))
+	})
+}
@@ -0,0 +1,541 @@
+package ringslice
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// WAL is a durable ring of variable size records kept in a directory of segment files.
+// Records are appended to the newest segment as a length, a CRC and the payload, rolling
+// to a new segment once segmentSize is reached. DeleteCount and Purge move a persisted
+// read cursor forward and remove segments once everything in them has been read.
+// The positions of unread records are held in memory in a Slice so reads are one ReadAt
+type WAL struct {
+	mu          sync.Mutex
+	dir         string
+	segmentSize int64
+	maxSegments int
+	segments    []*walSegment // oldest first, the last is written to
+	positions   *Slice[walPos]
+	sync        SyncPolicy
+	stop        chan struct{}
+	closed      bool
+
+	cursorID    uint64
+	cursorOff   int64
+	cursorDirty bool // the cursor file is behind cursorID and cursorOff or not yet fsynced
+	dirDirty    bool // files were created, renamed or removed since the directory was fsynced
+}
+
+type walSegment struct {
+	id    uint64
+	file  *os.File
+	size  int64
+	dirty bool // written since the last fsync
+}
+
+// walPos locates an unread record's payload
+type walPos struct {
+	seg  *walSegment
+	off  int64 // of the record header
+	size int
+}
+
+const (
+	walHeaderSize = 8 // uint32 length then the uint32 crc of the length and payload
+	walCursorSize = 20
+	walSuffix     = ".seg"
+	walCursorName = "cursor"
+)
+
+// OpenWAL opens the WAL in dir, creating it if needed, and recovers whatever was
+// written before. Records after the cursor are checked against their CRC and the
+// first one that is short, empty or fails, as left by a torn write, is truncated away
+// along with the rest of its segment, and a cursor past the end of its segment counts
+// it as all read. Records read but not yet persisted by the cursor, under SyncNever or
+// SyncInterval, are read again after a crash. maxSegments of 0 is unbounded, otherwise Append
+// returns ErrFull when it would need more. WithSync and WithSyncInterval pick the
+// fsync policy, SyncEveryWrite by default, and WithClock the clock for SyncInterval
+func OpenWAL(dir string, segmentSize int64, maxSegments int, opts ...Option) (*WAL, error) {
+	c := newConfig(opts)
+	if segmentSize <= 0 || maxSegments < 0 || (c.sync == SyncInterval && c.syncInterval <= 0) {
+		return nil, fmt.Errorf("ringslice: segment size and sync interval must be positive")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	w := &WAL{
+		dir:         dir,
+		segmentSize: segmentSize,
+		maxSegments: maxSegments,
+		positions:   NewSlice[walPos](64, false, nil, WithFullPolicy(FullGrow)),
+		sync:        c.sync,
+	}
+	if err := w.recover(); err != nil {
+		w.closeFiles()
+		return nil, err
+	}
+	if c.sync == SyncInterval {
+		clock := c.clock
+		if clock == nil {
+			clock = RealClock
+		}
+		w.stop = make(chan struct{})
+		ticker := clock.NewTicker(c.syncInterval)
+		go func() {
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C():
+					w.mu.Lock()
+					if !w.closed {
+						w.syncDirty()
+					}
+					w.mu.Unlock()
+				case <-w.stop:
+					return
+				}
+			}
+		}()
+	}
+	return w, nil
+}
+
+// Append writes a record, returns ErrFull if a new segment is needed and maxSegments are
+// in use. Empty records are refused with ErrRecordSize so recovery can tell them from zeroes
+func (w *WAL) Append(record []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return os.ErrClosed
+	}
+	if len(record) == 0 {
+		return ErrRecordSize
+	}
+	seg := w.active()
+	if seg.size > 0 && seg.size+walHeaderSize+int64(len(record)) > w.segmentSize {
+		if w.maxSegments > 0 && len(w.segments) >= w.maxSegments {
+			return ErrFull
+		}
+		var err error
+		if seg, err = w.roll(); err != nil {
+			return err
+		}
+	}
+	buf := make([]byte, walHeaderSize+len(record))
+	binary.LittleEndian.PutUint32(buf, uint32(len(record)))
+	copy(buf[walHeaderSize:], record)
+	binary.LittleEndian.PutUint32(buf[4:], walChecksum(buf))
+	if _, err := seg.file.WriteAt(buf, seg.size); err != nil {
+		seg.file.Truncate(seg.size) // best effort, recovery drops it anyway
+		return err
+	}
+	w.positions.Append(walPos{seg: seg, off: seg.size, size: len(record)})
+	seg.size += int64(len(buf))
+	seg.dirty = true
+	if w.sync == SyncEveryWrite {
+		return w.syncDirty()
+	}
+	return nil
+}
+
+// DeleteCount reads and deletes count of the oldest records, see Slice.DeleteCount
+func (w *WAL) DeleteCount(count int) ([][]byte, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil, os.ErrClosed
+	}
+	return w.deleteCount(count)
+}
+
+// Purge deletes the leading records with key <= want, binary searching like Slice.Purge
+// so records must be in key order
+func (w *WAL) Purge(want int64, key func([]byte) int64) ([][]byte, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil, os.ErrClosed
+	}
+	var readErr error
+	n := w.positions.search(func(p walPos) bool {
+		if readErr != nil {
+			return true
+		}
+		rec, err := w.read(p)
+		if err != nil {
+			readErr = err
+			return true
+		}
+		return key(rec) > want
+	})
+	if readErr != nil {
+		return nil, readErr
+	}
+	return w.deleteCount(n)
+}
+
+// At reads the record i away from the oldest
+func (w *WAL) At(i int) ([]byte, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil, os.ErrClosed
+	}
+	p, err := w.positions.At(i)
+	if err != nil {
+		return nil, err
+	}
+	return w.read(p)
+}
+
+// Len is the number of unread records
+func (w *WAL) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.positions.Len()
+}
+
+// Sync fsyncs anything outstanding whatever the policy
+func (w *WAL) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return os.ErrClosed
+	}
+	return w.syncDirty()
+}
+
+// Close syncs and closes the segment files, closing again does nothing. Everything
+// else returns os.ErrClosed afterwards
+func (w *WAL) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.stop != nil {
+		close(w.stop)
+	}
+	err := w.syncDirty()
+	if cerr := w.closeFiles(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// deleteCount moves the cursor before forgetting the records, so if that fails nothing has
+// changed and the records are still there to be read. Segments are only removed once the
+// cursor past them is written, see moveCursor
+func (w *WAL) deleteCount(count int) ([][]byte, error) {
+	if n := w.positions.Len(); count > n {
+		count = n // save us some time
+	}
+	l := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		p, _ := w.positions.At(i)
+		rec, err := w.read(p)
+		if err != nil {
+			return nil, err
+		}
+		l = append(l, rec)
+	}
+	if count == 0 {
+		return l, nil
+	}
+
+	// the cursor goes to the next unread record, or the end of what's written
+	seg, off := w.active(), w.active().size
+	if next, err := w.positions.At(count); err == nil {
+		seg, off = next.seg, next.off
+	}
+	if err := w.moveCursor(seg.id, off); err != nil {
+		return nil, err
+	}
+	w.positions.DeleteCount(count)
+	return l, nil
+}
+
+// removeRead removes the segments before the one the written cursor is in
+func (w *WAL) removeRead() {
+	for len(w.segments) > 1 && w.segments[0].id < w.cursorID {
+		old := w.segments[0]
+		old.file.Close()
+		w.segments = w.segments[1:]
+		w.dirDirty = true
+		os.Remove(w.segmentPath(old.id)) // if this fails recovery removes it instead
+	}
+}
+
+// moveCursor records the new read position. Under SyncEveryWrite it is written and
+// fsynced now, SyncNever writes it now without fsyncing and SyncInterval leaves it for
+// the next tick. Segments it passes are removed once it is written, so a crash before
+// that can't lose unread records, though read ones may be read again. On failure the
+// previous position is kept
+func (w *WAL) moveCursor(id uint64, off int64) error {
+	prevID, prevOff, prevDirty := w.cursorID, w.cursorOff, w.cursorDirty
+	w.cursorID, w.cursorOff, w.cursorDirty = id, off, true
+	var err error
+	switch w.sync {
+	case SyncEveryWrite:
+		err = w.syncDirty()
+	case SyncNever:
+		if err = w.writeCursor(false); err == nil {
+			w.removeRead()
+		}
+	}
+	if err != nil {
+		w.cursorID, w.cursorOff, w.cursorDirty = prevID, prevOff, prevDirty
+	}
+	return err
+}
+
+func (w *WAL) read(p walPos) ([]byte, error) {
+	rec := make([]byte, p.size)
+	_, err := p.seg.file.ReadAt(rec, p.off+walHeaderSize)
+	return rec, err
+}
+
+func (w *WAL) active() *walSegment {
+	return w.segments[len(w.segments)-1]
+}
+
+// roll starts a new segment. It doesn't sync, the old segment stays dirty until syncDirty
+// and the directory entry is covered by dirDirty
+func (w *WAL) roll() (*walSegment, error) {
+	id := w.cursorID // nothing left, carry on from where the cursor is so recovery keeps it
+	if len(w.segments) > 0 {
+		id = w.active().id + 1
+	}
+	f, err := os.OpenFile(w.segmentPath(id), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	seg := &walSegment{id: id, file: f}
+	w.segments = append(w.segments, seg)
+	w.dirDirty = true
+	return seg, nil
+}
+
+// syncDirty fsyncs the segments written to since their last sync and the cursor if it moved,
+// removing the segments it passed. Then the directory, so new segments, removals and the
+// cursor rename survive too
+func (w *WAL) syncDirty() error {
+	for _, s := range w.segments {
+		if !s.dirty {
+			continue
+		}
+		if err := s.file.Sync(); err != nil {
+			return err
+		}
+		s.dirty = false
+	}
+	if w.cursorDirty {
+		if err := w.writeCursor(true); err != nil {
+			return err
+		}
+		w.cursorDirty = false
+		w.removeRead()
+	}
+	if w.dirDirty {
+		if err := syncDir(w.dir); err != nil {
+			return err
+		}
+		w.dirDirty = false
+	}
+	return nil
+}
+
+// writeCursor replaces the cursor file atomically with a rename, fsyncing the new file
+// first if asked. The rename itself is only durable once the directory is synced
+func (w *WAL) writeCursor(fsync bool) error {
+	buf := make([]byte, walCursorSize)
+	binary.LittleEndian.PutUint64(buf, w.cursorID)
+	binary.LittleEndian.PutUint64(buf[8:], uint64(w.cursorOff))
+	binary.LittleEndian.PutUint32(buf[16:], crc32.ChecksumIEEE(buf[:16]))
+	tmp := filepath.Join(w.dir, walCursorName+".tmp")
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(buf)
+	if err == nil && fsync {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	w.dirDirty = true
+	return os.Rename(tmp, filepath.Join(w.dir, walCursorName))
+}
+
+func (w *WAL) readCursor() (id uint64, off int64, ok bool, err error) {
+	buf, err := os.ReadFile(filepath.Join(w.dir, walCursorName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, 0, false, nil
+	}
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if len(buf) != walCursorSize || crc32.ChecksumIEEE(buf[:16]) != binary.LittleEndian.Uint32(buf[16:]) {
+		return 0, 0, false, ErrCorrupt
+	}
+	return binary.LittleEndian.Uint64(buf), int64(binary.LittleEndian.Uint64(buf[8:])), true, nil
+}
+
+// recover opens the segments, drops any already read and rebuilds positions
+func (w *WAL) recover() error {
+	ids, err := w.segmentIDs()
+	if err != nil {
+		return err
+	}
+	cursorID, cursorOff, ok, err := w.readCursor()
+	if err != nil {
+		return err
+	}
+	if !ok && len(ids) > 0 {
+		cursorID, cursorOff = ids[0], 0
+	}
+	w.cursorID, w.cursorOff = cursorID, cursorOff
+	for _, id := range ids {
+		if id < cursorID {
+			// fully read, the process died between moving the cursor and removing it
+			if err := os.Remove(w.segmentPath(id)); err != nil {
+				return err
+			}
+			w.dirDirty = true
+			continue
+		}
+		f, err := os.OpenFile(w.segmentPath(id), os.O_RDWR, 0644)
+		if err != nil {
+			return err
+		}
+		seg := &walSegment{id: id, file: f}
+		w.segments = append(w.segments, seg)
+		from := int64(0)
+		if id == cursorID {
+			from = cursorOff
+		}
+		if err := w.scan(seg, from); err != nil {
+			return err
+		}
+	}
+	if len(w.segments) == 0 {
+		if _, err := w.roll(); err != nil {
+			return err
+		}
+	}
+	if w.sync == SyncEveryWrite {
+		return w.syncDirty()
+	}
+	return nil
+}
+
+// scan indexes the valid records of seg from off and truncates at the first torn one.
+// The length is part of the CRC and may not be zero, so zeroes left by a crash after the
+// file grew don't pass as records
+func (w *WAL) scan(seg *walSegment, off int64) error {
+	info, err := seg.file.Stat()
+	if err != nil {
+		return err
+	}
+	end := info.Size()
+	if off > end {
+		// the cursor made it to disk but the records it passed didn't, as can happen under
+		// SyncNever, so everything that is there has been read
+		off = end
+	}
+	header := make([]byte, walHeaderSize)
+	for off+walHeaderSize <= end {
+		if _, err := seg.file.ReadAt(header, off); err != nil {
+			return err
+		}
+		size := int64(binary.LittleEndian.Uint32(header))
+		if size == 0 || off+walHeaderSize+size > end {
+			break
+		}
+		buf := make([]byte, walHeaderSize+size)
+		if _, err := seg.file.ReadAt(buf, off); err != nil {
+			return err
+		}
+		if walChecksum(buf) != binary.LittleEndian.Uint32(header[4:]) {
+			break
+		}
+		w.positions.Append(walPos{seg: seg, off: off, size: int(size)})
+		off += walHeaderSize + size
+	}
+	seg.size = off
+	if off < end {
+		seg.dirty = true
+		return seg.file.Truncate(off)
+	}
+	return nil
+}
+
+// walChecksum covers the length and payload of an encoded record, skipping the crc itself
+func walChecksum(record []byte) uint32 {
+	crc := crc32.ChecksumIEEE(record[:4])
+	return crc32.Update(crc, crc32.IEEETable, record[walHeaderSize:])
+}
+
+func (w *WAL) segmentIDs() ([]uint64, error) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil, err
+	}
+	var ids []uint64
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasSuffix(name, walSuffix) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, walSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+func (w *WAL) segmentPath(id uint64) string {
+	return filepath.Join(w.dir, fmt.Sprintf("%020d%s", id, walSuffix))
+}
+
+func (w *WAL) closeFiles() error {
+	var err error
+	for _, s := range w.segments {
+		if cerr := s.file.Close(); err == nil {
+			err = cerr
+		}
+	}
+	w.segments = nil
+	return err
+}
+
+// syncDir fsyncs a directory so entries created, renamed or removed in it are durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cerr := d.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,333 @@
+package ringslice
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func walContents(t *testing.T, w *WAL) []string {
+	got := []string{}
+	for i := 0; i < w.Len(); i++ {
+		r, err := w.At(i)
+		require.NoError(t, err)
+		got = append(got, string(r))
+	}
+	return got
+}
+
+func walAppend(t *testing.T, w *WAL, records ...string) {
+	for _, r := range records {
+		require.NoError(t, w.Append([]byte(r)))
+	}
+}
+
+func walSegments(t *testing.T, dir string) int {
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+walSuffix))
+	require.NoError(t, err)
+	return len(matches)
+}
+
+func TestWALReopen(t *testing.T) {
+	dir := t.TempDir()
+	w, err := OpenWAL(dir, 1024, 0)
+	require.NoError(t, err)
+	walAppend(t, w, "a", "bb", "ccc", "dddd")
+	got, err := w.DeleteCount(2)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("a"), []byte("bb")}, got)
+	require.NoError(t, w.Close())
+
+	w, err = OpenWAL(dir, 1024, 0)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ccc", "dddd"}, walContents(t, w))
+	walAppend(t, w, "e")
+	require.NoError(t, w.Close())
+
+	w, err = OpenWAL(dir, 1024, 0)
+	require.NoError(t, err)
+	defer w.Close()
+	require.Equal(t, []string{"ccc", "dddd", "e"}, walContents(t, w))
+	got, err = w.DeleteCount(10)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	require.Equal(t, 0, w.Len())
+	_, err = w.At(0)
+	require.Equal(t, ErrOutOfRange, err)
+}
+
+func TestWALSegments(t *testing.T) {
+	dir := t.TempDir()
+	// each record is 8 bytes of header and 4 of payload, so 2 fit in a segment
+	w, err := OpenWAL(dir, 24, 3, WithSync(SyncNever))
+	require.NoError(t, err)
+	walAppend(t, w, "0000", "1111", "2222", "3333", "4444", "5555")
+	require.Equal(t, 3, walSegments(t, dir))
+	require.Equal(t, ErrFull, w.Append([]byte("6666")))
+
+	// reading into the second segment removes the first
+	_, err = w.DeleteCount(3)
+	require.NoError(t, err)
+	require.Equal(t, 2, walSegments(t, dir))
+	walAppend(t, w, "6666")
+	require.Equal(t, []string{"3333", "4444", "5555", "6666"}, walContents(t, w))
+	require.NoError(t, w.Close())
+
+	w, err = OpenWAL(dir, 24, 3)
+	require.NoError(t, err)
+	defer w.Close()
+	require.Equal(t, []string{"3333", "4444", "5555", "6666"}, walContents(t, w))
+
+	// reading everything keeps only the segment being written
+	_, err = w.DeleteCount(4)
+	require.NoError(t, err)
+	require.Equal(t, 1, walSegments(t, dir))
+}
+
+func TestWALSegmentsSyncInterval(t *testing.T) {
+	dir := t.TempDir()
+	clock := NewManualClock(epoch)
+	w, err := OpenWAL(dir, 24, 0, WithSyncInterval(time.Second), WithClock(clock))
+	require.NoError(t, err)
+	defer w.Close()
+	walAppend(t, w, "0000", "1111", "2222")
+	require.Equal(t, 2, walSegments(t, dir))
+
+	// the first segment stays until the cursor past it is written
+	_, err = w.DeleteCount(2)
+	require.NoError(t, err)
+	require.Equal(t, 2, walSegments(t, dir))
+	clock.Advance(time.Second)
+	for isDirty(w) {
+		time.Sleep(time.Millisecond)
+	}
+	require.Equal(t, 1, walSegments(t, dir))
+	require.Equal(t, []string{"2222"}, walContents(t, w))
+}
+
+func TestWALRecovery(t *testing.T) {
+	tests := []struct {
+		name    string
+		damage  func(t *testing.T, dir string)
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "torn payload",
+			damage: func(t *testing.T, dir string) {
+				truncateLast(t, dir, -2)
+			},
+			want: []string{"bb", "ccc"},
+		},
+		{
+			name: "torn header",
+			damage: func(t *testing.T, dir string) {
+				truncateLast(t, dir, -(4 + walHeaderSize - 3)) // 3 bytes of the header left
+			},
+			want: []string{"bb", "ccc"},
+		},
+		{
+			name: "bad crc drops the rest of the segment",
+			damage: func(t *testing.T, dir string) {
+				// "bb" starts after "a", its payload after its header
+				corruptAt(t, filepath.Join(dir, "00000000000000000000"+walSuffix), walHeaderSize+1+walHeaderSize)
+			},
+			want: []string{},
+		},
+		{
+			name: "zero filled tail",
+			damage: func(t *testing.T, dir string) {
+				// as left when the file grew but the data never made it
+				f, err := os.OpenFile(filepath.Join(dir, "00000000000000000000"+walSuffix), os.O_WRONLY|os.O_APPEND, 0)
+				require.NoError(t, err)
+				_, err = f.Write(make([]byte, 64))
+				require.NoError(t, err)
+				require.NoError(t, f.Close())
+			},
+			want: []string{"bb", "ccc", "dddd"},
+		},
+		{
+			name: "cursor past the end",
+			damage: func(t *testing.T, dir string) {
+				// the cursor made it to disk but the records it read didn't
+				require.NoError(t, os.Truncate(filepath.Join(dir, "00000000000000000000"+walSuffix), 4))
+			},
+			want: []string{},
+		},
+		{
+			name: "corrupt cursor",
+			damage: func(t *testing.T, dir string) {
+				corruptAt(t, filepath.Join(dir, walCursorName), 0)
+			},
+			wantErr: ErrCorrupt,
+		},
+		{
+			name: "missing cursor reads from the start",
+			damage: func(t *testing.T, dir string) {
+				require.NoError(t, os.Remove(filepath.Join(dir, walCursorName)))
+			},
+			want: []string{"a", "bb", "ccc", "dddd"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			w, err := OpenWAL(dir, 1024, 0)
+			require.NoError(t, err)
+			walAppend(t, w, "a", "bb", "ccc", "dddd")
+			_, err = w.DeleteCount(1)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+			tt.damage(t, dir)
+
+			w, err = OpenWAL(dir, 1024, 0)
+			require.Equal(t, tt.wantErr, err)
+			if err != nil {
+				return
+			}
+			defer w.Close()
+			require.Equal(t, tt.want, walContents(t, w))
+			// and it carries on from there
+			walAppend(t, w, "z")
+			require.Equal(t, append(tt.want, "z"), walContents(t, w))
+		})
+	}
+}
+
+// truncateLast cuts by bytes off the end of the newest segment
+func truncateLast(t *testing.T, dir string, by int64) {
+	path := filepath.Join(dir, "00000000000000000000"+walSuffix)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path, info.Size()+by))
+}
+
+func TestWALClose(t *testing.T) {
+	w, err := OpenWAL(t.TempDir(), 1024, 0, WithSyncInterval(time.Second), WithClock(NewManualClock(epoch)))
+	require.NoError(t, err)
+	walAppend(t, w, "a")
+	require.Equal(t, ErrRecordSize, w.Append(nil))
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close())
+
+	require.Equal(t, os.ErrClosed, w.Append([]byte("b")))
+	_, err = w.At(0)
+	require.Equal(t, os.ErrClosed, err)
+	_, err = w.DeleteCount(1)
+	require.Equal(t, os.ErrClosed, err)
+	_, err = w.Purge(0, func([]byte) int64 { return 0 })
+	require.Equal(t, os.ErrClosed, err)
+	require.Equal(t, os.ErrClosed, w.Sync())
+}
+
+func TestWALCursorFailure(t *testing.T) {
+	dir := t.TempDir()
+	w, err := OpenWAL(dir, 1024, 0)
+	require.NoError(t, err)
+	defer w.Close()
+	walAppend(t, w, "a", "b")
+
+	// a directory where the cursor is written makes it fail
+	blocker := filepath.Join(dir, walCursorName+".tmp")
+	require.NoError(t, os.Mkdir(blocker, 0755))
+	_, err = w.DeleteCount(1)
+	require.Error(t, err)
+	require.Equal(t, []string{"a", "b"}, walContents(t, w))
+
+	require.NoError(t, os.Remove(blocker))
+	got, err := w.DeleteCount(1)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("a")}, got)
+	require.Equal(t, []string{"b"}, walContents(t, w))
+}
+
+func TestWALPurge(t *testing.T) {
+	w, err := OpenWAL(t.TempDir(), 16, 0)
+	require.NoError(t, err)
+	defer w.Close()
+	for i := 1; i <= 6; i++ {
+		walAppend(t, w, strconv.Itoa(i*10))
+	}
+	key := func(r []byte) int64 {
+		n, _ := strconv.ParseInt(string(r), 10, 64)
+		return n
+	}
+
+	got, err := w.Purge(5, key)
+	require.NoError(t, err)
+	require.Empty(t, got)
+	got, err = w.Purge(35, key)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("10"), []byte("20"), []byte("30")}, got)
+	require.Equal(t, []string{"40", "50", "60"}, walContents(t, w))
+	got, err = w.Purge(100, key)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	require.Equal(t, 0, w.Len())
+}
+
+func TestWALSyncPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		wantDirty bool
+	}{
+		{name: "every write"},
+		{name: "never", opts: []Option{WithSync(SyncNever)}, wantDirty: true},
+		{name: "interval", opts: []Option{WithSyncInterval(time.Second)}, wantDirty: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewManualClock(epoch)
+			w, err := OpenWAL(t.TempDir(), 1024, 0, append(tt.opts, WithClock(clock))...)
+			require.NoError(t, err)
+			defer w.Close()
+			walAppend(t, w, "a", "b")
+			require.Equal(t, tt.wantDirty, isDirty(w))
+			_, err = w.DeleteCount(1)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantDirty, isDirty(w))
+
+			clock.Advance(time.Second)
+			if tt.name == "interval" {
+				for isDirty(w) {
+					time.Sleep(time.Millisecond)
+				}
+			} else {
+				require.Equal(t, tt.wantDirty, isDirty(w))
+			}
+			require.NoError(t, w.Sync())
+			require.False(t, isDirty(w))
+		})
+	}
+
+	_, err := OpenWAL(t.TempDir(), 1024, 0, WithSync(SyncInterval))
+	require.Error(t, err)
+}
+
+func isDirty(w *WAL) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, s := range w.segments {
+		if s.dirty {
+			return true
+		}
+	}
+	return w.cursorDirty || w.dirDirty
+}
+
+func corruptAt(t *testing.T, path string, off int64) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	require.NoError(t, err)
+	defer f.Close()
+	b := make([]byte, 1)
+	_, err = f.ReadAt(b, off)
+	require.NoError(t, err)
+	b[0] ^= 0xff
+	_, err = f.WriteAt(b, off)
+	require.NoError(t, err)
+}
@@ -0,0 +1,119 @@
+package ringslice
+
+// Window is a ring that keeps aggregates of value over its contents as entries are
+// appended and deleted. Sum, Count and Mean are O(1) and Min and Max are amortized O(1)
+// using a monotonic deque per side. The sum is a running total so can drift slightly
+// with floating point values over a very long run
+type Window[T any] struct {
+	ring  *Slice[T]
+	value func(T) float64
+	sum   float64
+	mins  *Slice[windowEntry] // increasing values, front is the min
+	maxs  *Slice[windowEntry] // decreasing values, front is the max
+	head  uint64              // seq of the oldest entry
+	tail  uint64              // seq of the next entry
+}
+
+type windowEntry struct {
+	seq   uint64
+	value float64
+}
+
+// NewWindow creates a window of capacity. WithFullPolicy(FullOverwrite) gives a window
+// over the last capacity entries
+func NewWindow[T any](capacity int, value func(T) float64, opts ...Option) *Window[T] {
+	return &Window[T]{
+		ring:  NewSlice[T](capacity, false, nil, opts...),
+		value: value,
+		mins:  NewSlice[windowEntry](capacity, false, nil, WithFullPolicy(FullGrow)),
+		maxs:  NewSlice[windowEntry](capacity, false, nil, WithFullPolicy(FullGrow)),
+	}
+}
+
+// Append adds an entry, any entry evicted to make room leaves the aggregates
+func (w *Window[T]) Append(value T) error {
+	evicted, ok, err := w.ring.AppendEvict(value)
+	if err != nil {
+		return err
+	}
+	if ok {
+		w.removed(evicted)
+	}
+	v := w.value(value)
+	w.sum += v
+	e := windowEntry{seq: w.tail, value: v}
+	w.tail++
+	for back, err := w.mins.Back(); err == nil && back.value >= v; back, err = w.mins.Back() {
+		w.mins.PopBack()
+	}
+	w.mins.Append(e)
+	for back, err := w.maxs.Back(); err == nil && back.value <= v; back, err = w.maxs.Back() {
+		w.maxs.PopBack()
+	}
+	w.maxs.Append(e)
+	return nil
+}
+
+// DeleteCount deletes count of the oldest entries, see Slice.DeleteCount
+func (w *Window[T]) DeleteCount(count int) []T {
+	l := w.ring.DeleteCount(count)
+	for _, v := range l {
+		w.removed(v)
+	}
+	return l
+}
+
+// Purge deletes all entries with key <= want, see Slice.Purge
+func (w *Window[T]) Purge(want int64, key func(T) int64) []T {
+	l := w.ring.Purge(want, key)
+	for _, v := range l {
+		w.removed(v)
+	}
+	return l
+}
+
+// Count is the number of entries in the window
+func (w *Window[T]) Count() int {
+	return w.ring.Len()
+}
+
+// Sum of value over the window
+func (w *Window[T]) Sum() float64 {
+	return w.sum
+}
+
+// Mean of value over the window, ErrEmpty if there is nothing to average
+func (w *Window[T]) Mean() (float64, error) {
+	n := w.ring.Len()
+	if n == 0 {
+		return 0, ErrEmpty
+	}
+	return w.sum / float64(n), nil
+}
+
+// Min of value over the window
+func (w *Window[T]) Min() (float64, error) {
+	e, err := w.mins.Front()
+	return e.value, err
+}
+
+// Max of value over the window
+func (w *Window[T]) Max() (float64, error) {
+	e, err := w.maxs.Front()
+	return e.value, err
+}
+
+// removed drops the oldest entry from the aggregates, deletes always happen oldest first
+func (w *Window[T]) removed(value T) {
+	w.sum -= w.value(value)
+	if e, err := w.mins.Front(); err == nil && e.seq == w.head {
+		w.mins.PopFront()
+	}
+	if e, err := w.maxs.Front(); err == nil && e.seq == w.head {
+		w.maxs.PopFront()
+	}
+	w.head++
+	if w.head == w.tail {
+		w.sum = 0 // empty, so shed any drift
+	}
+}
@@ -0,0 +1,114 @@
+package ringslice
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		appends []float64
+		delete  int
+		wantMin float64
+		wantMax float64
+		wantSum float64
+	}{
+		{name: "increasing", appends: []float64{1, 2, 3, 4}, wantMin: 1, wantMax: 4, wantSum: 10},
+		{name: "decreasing", appends: []float64{4, 3, 2, 1}, wantMin: 1, wantMax: 4, wantSum: 10},
+		{name: "delete min", appends: []float64{1, 5, 3, 4}, delete: 1, wantMin: 3, wantMax: 5, wantSum: 12},
+		{name: "delete max", appends: []float64{5, 1, 3, 4}, delete: 1, wantMin: 1, wantMax: 4, wantSum: 8},
+		{name: "equal values", appends: []float64{2, 2, 2}, delete: 2, wantMin: 2, wantMax: 2, wantSum: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewWindow[float64](4, func(v float64) float64 { return v })
+			for _, v := range tt.appends {
+				require.NoError(t, w.Append(v))
+			}
+			w.DeleteCount(tt.delete)
+			min, err := w.Min()
+			require.NoError(t, err)
+			require.Equal(t, tt.wantMin, min)
+			max, err := w.Max()
+			require.NoError(t, err)
+			require.Equal(t, tt.wantMax, max)
+			require.Equal(t, tt.wantSum, w.Sum())
+			mean, err := w.Mean()
+			require.NoError(t, err)
+			require.Equal(t, tt.wantSum/float64(w.Count()), mean)
+		})
+	}
+}
+
+func TestWindowEmpty(t *testing.T) {
+	w := NewWindow[float64](2, func(v float64) float64 { return v })
+	w.Append(1)
+	w.DeleteCount(1)
+	_, err := w.Min()
+	require.Equal(t, ErrEmpty, err)
+	_, err = w.Max()
+	require.Equal(t, ErrEmpty, err)
+	_, err = w.Mean()
+	require.Equal(t, ErrEmpty, err)
+	require.Equal(t, 0.0, w.Sum())
+}
+
+func TestWindowPurge(t *testing.T) {
+	w := NewWindow[sample](4, func(s sample) float64 { return float64(s.val) })
+	for i, v := range []int{7, 1, 9, 3} {
+		w.Append(sample{epoch.Add(time.Duration(i) * time.Second), v})
+	}
+	w.Purge(epoch.Add(time.Second).UnixNano(), func(s sample) int64 { return s.at.UnixNano() })
+	min, _ := w.Min()
+	max, _ := w.Max()
+	require.Equal(t, 3.0, min)
+	require.Equal(t, 9.0, max)
+	require.Equal(t, 12.0, w.Sum())
+}
+
+// TestWindowMatchesBruteForce compares against a rescan over a sliding window of the last 8
+func TestWindowMatchesBruteForce(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	w := NewWindow[int](8, func(v int) float64 { return float64(v) }, WithFullPolicy(FullOverwrite))
+	var all []int
+	for i := 0; i < 1000; i++ {
+		if rnd.Intn(5) == 0 && len(all) > 0 {
+			n := rnd.Intn(3)
+			w.DeleteCount(n)
+			if n > len(all) {
+				n = len(all)
+			}
+			all = all[n:]
+		} else {
+			v := rnd.Intn(100)
+			require.NoError(t, w.Append(v))
+			all = append(all, v)
+			if len(all) > 8 {
+				all = all[1:]
+			}
+		}
+		if len(all) == 0 {
+			continue
+		}
+		wantMin, wantMax, wantSum := all[0], all[0], 0
+		for _, v := range all {
+			if v < wantMin {
+				wantMin = v
+			}
+			if v > wantMax {
+				wantMax = v
+			}
+			wantSum += v
+		}
+		min, _ := w.Min()
+		max, _ := w.Max()
+		require.Equal(t, float64(wantMin), min)
+		require.Equal(t, float64(wantMax), max)
+		require.Equal(t, float64(wantSum), w.Sum())
+		require.Equal(t, len(all), w.Count())
+	}
+}